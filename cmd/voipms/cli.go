@@ -16,6 +16,8 @@ type options struct {
 	ApiKey     string
 	ApiUrl     string
 	ApiTimeout time.Duration
+	UseGet     bool
+	Debug      bool
 }
 
 var opts options
@@ -24,9 +26,10 @@ var vms *voipms.VoIpMsApi
 func main() {
 	var err error
 	rootCmd := &cobra.Command{
-		Use:   "voipms",
-		Short: "CLI for VoIP.ms API",
-		Run:   help,
+		Use:              "voipms",
+		Short:            "CLI for VoIP.ms API",
+		Run:              help,
+		PersistentPreRun: newClient,
 	}
 
 	opts.Username = os.Getenv("VOIPMS_USERNAME")
@@ -45,21 +48,8 @@ func main() {
 	rootCmd.PersistentFlags().StringVarP(&opts.ApiKey, "api-key", "p", opts.ApiKey, "VoIP.ms API key")
 	rootCmd.PersistentFlags().StringVar(&opts.ApiUrl, "api-url", opts.ApiUrl, "VoIP.ms API URL")
 	rootCmd.PersistentFlags().DurationVar(&opts.ApiTimeout, "api-timeout", opts.ApiTimeout, "Timeout for HTTP requests, defaults to 2")
-
-	if len(opts.Username) == 0 || len(opts.ApiKey) == 0 {
-		log.Fatalln("username and API key are both required")
-	}
-
-	if opts.ApiUrl != "" {
-		vms = &voipms.VoIpMsApi{
-			ApiUsername: opts.Username,
-			ApiPassword: opts.ApiKey,
-			ApiUrl:      opts.ApiUrl,
-			ApiTimeout:  opts.ApiTimeout,
-		}
-	} else {
-		vms = voipms.NewVoIpMsClient(opts.Username, opts.ApiKey)
-	}
+	rootCmd.PersistentFlags().BoolVar(&opts.UseGet, "use-get", false, "Send credentials in the query string instead of a POST body")
+	rootCmd.PersistentFlags().BoolVar(&opts.Debug, "debug", false, "Dump HTTP requests and responses to stderr, credentials redacted")
 
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "setdidpop DID POP",
@@ -110,6 +100,17 @@ func main() {
 		Run:   getDidInfoForClient,
 	})
 
+	rootCmd.AddCommand(newClientCommand())
+	rootCmd.AddCommand(newBillingCommand())
+	rootCmd.AddCommand(newSubAccountCommand())
+	rootCmd.AddCommand(newBalanceCommand())
+	rootCmd.AddCommand(newTransactionsCommand())
+	rootCmd.AddCommand(newCDRCommand())
+	rootCmd.AddCommand(newSMSCommand())
+	rootCmd.AddCommand(newDIDCommand())
+	rootCmd.AddCommand(newApplyCommand())
+	rootCmd.AddCommand(newPlanCommand())
+
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatal(err)
 	}
@@ -119,6 +120,24 @@ func help(cmd *cobra.Command, _ []string) {
 	_ = cmd.Help()
 }
 
+func newClient(_ *cobra.Command, _ []string) {
+	if len(opts.Username) == 0 || len(opts.ApiKey) == 0 {
+		log.Fatalln("username and API key are both required")
+	}
+
+	vms = voipms.NewVoIpMsClient(opts.Username, opts.ApiKey)
+	vms.ApiTimeout = opts.ApiTimeout
+	vms.UseGet = opts.UseGet
+
+	if opts.ApiUrl != "" {
+		vms.ApiUrl = opts.ApiUrl
+	}
+
+	if opts.Debug {
+		vms.Use(voipms.WithDump(os.Stderr))
+	}
+}
+
 func setDidPop(_ *cobra.Command, args []string) {
 	did := args[0]
 	pop := args[1]
@@ -139,14 +158,26 @@ func printServerInfo(server *voipms.ServerInfo) {
 	fmt.Printf("%d %s (%s : %s) %s\n", server.ServerPOP, server.ServerName, server.ServerHostname, server.ServerIP, recommended)
 }
 
+func printClientInfo(client *voipms.ClientInfo) {
+	fmt.Printf("%s %s <%s>", client.Client, client.Name(), client.Email)
+	if client.Company != "" {
+		fmt.Printf(" (%s)", client.Company)
+	}
+	fmt.Printf(" balance=%v threshold=%v billing_type=%d status=%s\n",
+		client.Balance, client.Threshold, client.BillingType, client.Status)
+}
+
 func getClients(_ *cobra.Command, args []string) {
 	var (
 		err     error
-		clients *voipms.BaseResponse
+		client  *voipms.ClientInfo
+		clients *voipms.GetClientsResponse
 	)
 
 	if len(args) == 1 {
-		clients, err = vms.GetClientOneClient(args[0])
+		if client, err = vms.GetClientOneClient(args[0]); err == nil {
+			clients = &voipms.GetClientsResponse{Clients: []voipms.ClientInfo{*client}}
+		}
 	} else {
 		clients, err = vms.GetClients()
 	}
@@ -155,7 +186,9 @@ func getClients(_ *cobra.Command, args []string) {
 		log.Fatalf("error while fetching clients: %v", err)
 	}
 
-	fmt.Printf("%v", clients)
+	for i := range clients.Clients {
+		printClientInfo(&clients.Clients[i])
+	}
 }
 
 func getDidInfo(_ *cobra.Command, args []string) {
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/ticpu/voipms-gorest/v1/didconfig"
+)
+
+type applyOptions struct {
+	File  string
+	Apply bool
+}
+
+var applyOpts applyOptions
+
+func newApplyCommand() *cobra.Command {
+	applyCmd := &cobra.Command{
+		Use:   "apply -f FILE",
+		Short: "Show the changes needed for the DIDs to match FILE, and make them with --apply",
+		Args:  cobra.NoArgs,
+		Run:   applyDIDConfig,
+	}
+	applyCmd.Flags().StringVarP(&applyOpts.File, "file", "f", "", "YAML file describing the DIDs, required")
+	applyCmd.Flags().BoolVar(&applyOpts.Apply, "apply", false, "Make the changes instead of only showing them")
+
+	return applyCmd
+}
+
+func newPlanCommand() *cobra.Command {
+	planCmd := &cobra.Command{
+		Use:   "plan -f FILE",
+		Short: "Show the changes needed for the DIDs to match FILE, exiting with 2 when apply has some and 3 when only drift apply cannot fix is left",
+		Args:  cobra.NoArgs,
+		Run:   planDIDConfig,
+	}
+	planCmd.Flags().StringVarP(&applyOpts.File, "file", "f", "", "YAML file describing the DIDs, required")
+
+	return planCmd
+}
+
+// loadPlan reads the configuration file and prints the plan for it.
+func loadPlan() *didconfig.Plan {
+	if applyOpts.File == "" {
+		log.Fatalln("a configuration file is required, use -f")
+	}
+
+	config, err := didconfig.LoadFile(applyOpts.File)
+	if err != nil {
+		log.Fatalf("error while reading configuration: %v", err)
+	}
+
+	plan, err := didconfig.NewPlan(context.Background(), vms, config)
+	if err != nil {
+		log.Fatalf("error while comparing DIDs: %v", err)
+	}
+
+	if err = plan.Write(os.Stdout); err != nil {
+		log.Fatalf("error while writing plan: %v", err)
+	}
+
+	return plan
+}
+
+func planDIDConfig(_ *cobra.Command, _ []string) {
+	plan := loadPlan()
+
+	switch {
+	case plan.HasChanges():
+		os.Exit(2)
+	case plan.HasDrift():
+		os.Exit(3)
+	}
+}
+
+func applyDIDConfig(_ *cobra.Command, _ []string) {
+	plan := loadPlan()
+	if !applyOpts.Apply || !plan.HasDrift() {
+		return
+	}
+
+	if err := plan.Apply(context.Background(), vms); err != nil {
+		log.Fatalf("error while applying changes: %v", err)
+	}
+
+	if plan.HasChanges() {
+		log.Println("changes applied")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	voipms "github.com/ticpu/voipms-gorest/v1"
+)
+
+type balanceOptions struct {
+	WarnBelow string
+	From      string
+	To        string
+}
+
+var balanceOpts balanceOptions
+
+func newBalanceCommand() *cobra.Command {
+	balanceCmd := &cobra.Command{
+		Use:   "balance",
+		Short: "Show the prepaid balance with today's and total usage",
+		Args:  cobra.NoArgs,
+		Run:   showBalance,
+	}
+	balanceCmd.Flags().StringVar(&balanceOpts.WarnBelow, "warn-below", "", "Exit with status 2 when the balance is under this amount")
+
+	return balanceCmd
+}
+
+func newTransactionsCommand() *cobra.Command {
+	transactionsCmd := &cobra.Command{
+		Use:   "transactions",
+		Short: "List the transactions posted on the account",
+		Args:  cobra.NoArgs,
+		Run:   listTransactions,
+	}
+
+	today := time.Now().UTC()
+	transactionsCmd.Flags().StringVar(&balanceOpts.From, "from", today.AddDate(0, 0, -30).Format("2006-01-02"), "First day, as YYYY-MM-DD")
+	transactionsCmd.Flags().StringVar(&balanceOpts.To, "to", today.Format("2006-01-02"), "Last day included, as YYYY-MM-DD")
+
+	return transactionsCmd
+}
+
+func parseDate(name string, text string) voipms.VoIpMsDate {
+	var date voipms.VoIpMsDate
+	if err := date.UnmarshalText([]byte(text)); err != nil {
+		log.Fatalf("invalid %s date %s: %v", name, text, err)
+	}
+	return date
+}
+
+func showBalance(_ *cobra.Command, _ []string) {
+	balance, err := vms.GetBalance(true)
+	if err != nil {
+		log.Fatalf("error while fetching balance: %v", err)
+	}
+
+	fmt.Printf("balance: %s\n", balance.CurrentBalance)
+	fmt.Printf("spent total: %s (%d calls, %s)\n", balance.SpentTotal, balance.CallsTotal, balance.TimeTotal)
+	fmt.Printf("spent today: %s (%d calls, %s)\n", balance.SpentToday, balance.CallsToday, balance.TimeToday)
+
+	if balanceOpts.WarnBelow != "" {
+		if threshold := parseAmount(balanceOpts.WarnBelow); balance.CurrentBalance < threshold {
+			log.Printf("balance %s is under %s", balance.CurrentBalance, threshold)
+			os.Exit(2)
+		}
+	}
+}
+
+func listTransactions(_ *cobra.Command, _ []string) {
+	from := parseDate("from", balanceOpts.From)
+	to := parseDate("to", balanceOpts.To)
+
+	response, err := vms.GetTransactionHistory(from, to)
+	if err != nil {
+		log.Fatalf("error while fetching transactions: %v", err)
+	}
+
+	for _, transaction := range response.Transactions {
+		fmt.Printf("%s %s %s %s %s\n", transaction.Date.Format("2006-01-02 15:04:05"), transaction.UniqueID,
+			transaction.Type, transaction.Amount, transaction.Description)
+	}
+}
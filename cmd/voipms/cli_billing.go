@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	voipms "github.com/ticpu/voipms-gorest/v1"
+)
+
+type billingOptions struct {
+	Description string
+	Test        bool
+	Email       string
+}
+
+var billingOpts billingOptions
+
+func newBillingCommand() *cobra.Command {
+	billingCmd := &cobra.Command{
+		Use:   "billing",
+		Short: "Reseller client balances, charges and payments",
+		Run:   help,
+	}
+
+	chargeCmd := &cobra.Command{
+		Use:   "charge CLIENT AMOUNT",
+		Short: "Post a charge on a client",
+		Args:  cobra.ExactArgs(2),
+		Run:   addCharge,
+	}
+
+	paymentCmd := &cobra.Command{
+		Use:   "payment CLIENT AMOUNT",
+		Short: "Post a payment on a client",
+		Args:  cobra.ExactArgs(2),
+		Run:   addPayment,
+	}
+
+	for _, cmd := range []*cobra.Command{chargeCmd, paymentCmd} {
+		cmd.Flags().StringVarP(&billingOpts.Description, "description", "d", "", "Description shown to the client")
+		cmd.Flags().BoolVar(&billingOpts.Test, "test", false, "Validate the call without posting anything")
+	}
+
+	thresholdCmd := &cobra.Command{
+		Use:   "threshold CLIENT [AMOUNT]",
+		Short: "Show or set the low balance threshold of a client",
+		Args:  cobra.RangeArgs(1, 2),
+		Run:   clientThreshold,
+	}
+	thresholdCmd.Flags().StringVar(&billingOpts.Email, "email", "", "Email notified when the balance goes under the threshold")
+
+	billingCmd.AddCommand(
+		&cobra.Command{
+			Use:   "balance CLIENT",
+			Short: "Show the balance of a client",
+			Args:  cobra.ExactArgs(1),
+			Run:   resellerBalance,
+		},
+		chargeCmd,
+		paymentCmd,
+		&cobra.Command{
+			Use:   "charges CLIENT",
+			Short: "List charges posted on a client",
+			Args:  cobra.ExactArgs(1),
+			Run:   listCharges,
+		},
+		&cobra.Command{
+			Use:   "deposits CLIENT",
+			Short: "List deposits posted on a client",
+			Args:  cobra.ExactArgs(1),
+			Run:   listDeposits,
+		},
+		&cobra.Command{
+			Use:   "packages CLIENT",
+			Short: "List the packages of a client",
+			Args:  cobra.ExactArgs(1),
+			Run:   listClientPackages,
+		},
+		thresholdCmd,
+	)
+
+	return billingCmd
+}
+
+func parseAmount(text string) voipms.VoIpMsMoney {
+	amount, err := voipms.ParseMoney(text)
+	if err != nil {
+		log.Fatalf("invalid amount: %v", err)
+	}
+	return amount
+}
+
+func resellerBalance(_ *cobra.Command, args []string) {
+	balance, err := vms.GetResellerBalance(args[0])
+	if err != nil {
+		log.Fatalf("error while fetching balance: %v", err)
+	}
+
+	fmt.Printf("balance: %s\n", balance.CurrentBalance)
+	fmt.Printf("spent total: %s (%d calls, %s)\n", balance.SpentTotal, balance.CallsTotal, balance.TimeTotal)
+	fmt.Printf("spent today: %s (%d calls, %s)\n", balance.SpentToday, balance.CallsToday, balance.TimeToday)
+}
+
+func addCharge(_ *cobra.Command, args []string) {
+	amount := parseAmount(args[1])
+	if _, err := vms.AddCharge(args[0], amount, billingOpts.Description, billingOpts.Test); err != nil {
+		log.Fatalf("error while adding charge: %v", err)
+	}
+	log.Printf("charged %s to client %s", amount, args[0])
+}
+
+func addPayment(_ *cobra.Command, args []string) {
+	amount := parseAmount(args[1])
+	if _, err := vms.AddPayment(args[0], amount, billingOpts.Description, billingOpts.Test); err != nil {
+		log.Fatalf("error while adding payment: %v", err)
+	}
+	log.Printf("credited %s to client %s", amount, args[0])
+}
+
+func printBillingEntries(entries []voipms.BillingEntry) {
+	for _, entry := range entries {
+		fmt.Printf("%s %s %s %s\n", entry.ID, entry.Date.Format("2006-01-02"), entry.Amount, entry.Description)
+	}
+}
+
+func listCharges(_ *cobra.Command, args []string) {
+	response, err := vms.GetCharges(args[0])
+	if err != nil {
+		log.Fatalf("error while fetching charges: %v", err)
+	}
+	printBillingEntries(response.Charges)
+}
+
+func listDeposits(_ *cobra.Command, args []string) {
+	response, err := vms.GetDeposits(args[0])
+	if err != nil {
+		log.Fatalf("error while fetching deposits: %v", err)
+	}
+	printBillingEntries(response.Deposits)
+}
+
+func listClientPackages(_ *cobra.Command, args []string) {
+	response, err := vms.GetClientPackages(args[0])
+	if err != nil {
+		log.Fatalf("error while fetching packages: %v", err)
+	}
+
+	for _, clientPackage := range response.Packages {
+		fmt.Printf("%s %s monthly=%s setup=%s markup=%s+%v%% free_minutes=%d\n",
+			clientPackage.Package, clientPackage.Name, clientPackage.MonthlyFee, clientPackage.SetupFee,
+			clientPackage.MarkupFixed, clientPackage.MarkupPercentage, clientPackage.FreeMinutes)
+	}
+}
+
+func clientThreshold(_ *cobra.Command, args []string) {
+	if len(args) == 2 {
+		amount := parseAmount(args[1])
+		if _, err := vms.SetClientThreshold(args[0], amount, billingOpts.Email); err != nil {
+			log.Fatalf("error while setting threshold: %v", err)
+		}
+		log.Printf("threshold of client %s set to %s", args[0], amount)
+		return
+	}
+
+	threshold, err := vms.GetClientThreshold(args[0])
+	if err != nil {
+		log.Fatalf("error while fetching threshold: %v", err)
+	}
+	fmt.Printf("threshold: %s email: %s\n", threshold.Threshold, threshold.Email)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+	voipms "github.com/ticpu/voipms-gorest/v1"
+	"github.com/ticpu/voipms-gorest/v1/cdrexport"
+	_ "modernc.org/sqlite"
+)
+
+type cdrOptions struct {
+	From        string
+	To          string
+	Format      string
+	Out         string
+	Timezone    float64
+	Account     string
+	Client      string
+	CallType    string
+	CallBilling string
+}
+
+var cdrOpts cdrOptions
+
+func newCDRCommand() *cobra.Command {
+	cdrCmd := &cobra.Command{
+		Use:   "cdr",
+		Short: "Call detail records",
+		Run:   help,
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export call detail records, resuming after the last record already in the output",
+		Args:  cobra.NoArgs,
+		Run:   exportCDR,
+	}
+
+	today := time.Now().Format("2006-01-02")
+	exportCmd.Flags().StringVar(&cdrOpts.From, "from", today, "First day, as YYYY-MM-DD")
+	exportCmd.Flags().StringVar(&cdrOpts.To, "to", today, "Last day included, as YYYY-MM-DD")
+	exportCmd.Flags().StringVar(&cdrOpts.Format, "format", "csv", "Output format: csv, jsonl or sqlite")
+	exportCmd.Flags().StringVar(&cdrOpts.Out, "out", "", "Output file, appended to when it already holds records")
+	exportCmd.Flags().Float64Var(&cdrOpts.Timezone, "timezone", 0, "Hours from UTC the dates are expressed in, the same as the output being resumed")
+	exportCmd.Flags().StringVar(&cdrOpts.Account, "account", "", "Only export calls of this account")
+	exportCmd.Flags().StringVar(&cdrOpts.Client, "client", "", "Only export calls of this reseller client")
+	exportCmd.Flags().StringVar(&cdrOpts.CallType, "call-type", "", "Only export calls of this type")
+	exportCmd.Flags().StringVar(&cdrOpts.CallBilling, "call-billing", "", "Only export calls with this billing, all, free or billed")
+	_ = exportCmd.MarkFlagRequired("out")
+
+	cdrCmd.AddCommand(exportCmd)
+
+	return cdrCmd
+}
+
+func exportCDR(_ *cobra.Command, _ []string) {
+	var (
+		exported int
+		skipped  int
+	)
+
+	filter := voipms.CDRFilter{
+		From:        parseDate("from", cdrOpts.From),
+		To:          parseDate("to", cdrOpts.To),
+		Timezone:    &cdrOpts.Timezone,
+		Account:     cdrOpts.Account,
+		Client:      cdrOpts.Client,
+		CallType:    cdrOpts.CallType,
+		CallBilling: cdrOpts.CallBilling,
+	}
+
+	writer, resume, err := cdrexport.Open(cdrOpts.Format, cdrOpts.Out, cdrOpts.Timezone)
+	if err != nil {
+		log.Fatalf("error while opening %s: %v", cdrOpts.Out, err)
+	}
+
+	if last := resume.Last(); !last.IsZero() {
+		log.Printf("resuming after %s", last.Format("2006-01-02 15:04:05"))
+		if day := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, filter.From.Location()); day.After(filter.From.Time) {
+			filter.From.Time = day
+		}
+	}
+
+	if filter.From.After(filter.To.Time) {
+		_ = writer.Close()
+		log.Printf("%s is up to date", cdrOpts.Out)
+		return
+	}
+
+	records := vms.GetCDR(filter)
+	for records.Next() {
+		cdr := records.CDR()
+		if resume.Exported(cdr) {
+			skipped++
+			continue
+		}
+
+		if err = writer.Write(cdr); err != nil {
+			_ = writer.Close()
+			log.Fatalf("error while writing %s: %v", cdrOpts.Out, err)
+		}
+		exported++
+	}
+
+	if err = writer.Close(); err != nil {
+		log.Fatalf("error while writing %s: %v", cdrOpts.Out, err)
+	}
+
+	if err = records.Err(); err != nil {
+		log.Fatalf("error while fetching CDR after %d records: %v", exported, err)
+	}
+
+	log.Printf("exported %d records to %s, %d already present", exported, cdrOpts.Out, skipped)
+}
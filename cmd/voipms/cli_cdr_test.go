@@ -0,0 +1,88 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	voipms "github.com/ticpu/voipms-gorest/v1"
+	"github.com/ticpu/voipms-gorest/v1/cdrexport"
+)
+
+func export(t *testing.T, path string, timezone float64, cdrs []voipms.CDR) (written int) {
+	t.Helper()
+
+	writer, resume, err := cdrexport.Open(cdrexport.FormatSQLite, path, timezone)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range cdrs {
+		if resume.Exported(&cdrs[i]) {
+			continue
+		}
+		if err = writer.Write(&cdrs[i]); err != nil {
+			t.Fatal(err)
+		}
+		written++
+	}
+
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return written
+}
+
+// TestSQLiteExport checks the export against the driver the CLI links.
+func TestSQLiteExport(t *testing.T) {
+	eastern := time.FixedZone("", -5*3600)
+	at := func(hour, minute, second int) voipms.VoIpMsDateTime {
+		return voipms.VoIpMsDateTime{Time: time.Date(2024, time.March, 1, hour, minute, second, 0, eastern)}
+	}
+	cdrs := []voipms.CDR{
+		{Date: at(9, 0, 0), UniqueID: "1", Disposition: "ANSWERED", Seconds: 12, Total: voipms.MustParseMoney("0.01")},
+		{Date: at(10, 30, 0), UniqueID: "2", Disposition: "ANSWERED"},
+		{Date: at(10, 30, 0), UniqueID: "3", Disposition: "BUSY"},
+		{Date: at(23, 59, 59), UniqueID: "4", Disposition: "FAILED"},
+	}
+
+	path := filepath.Join(t.TempDir(), "cdr.sqlite")
+	if written := export(t, path, -5, cdrs[:2]); written != 2 {
+		t.Fatalf("expected 2 records written, got %d", written)
+	}
+	if written := export(t, path, -5, cdrs); written != 2 {
+		t.Fatalf("expected 2 records written on resume, got %d", written)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT uniqueid FROM cdr ORDER BY rowid`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if !reflect.DeepEqual(ids, []string{"1", "2", "3", "4"}) {
+		t.Fatalf("unexpected records %v", ids)
+	}
+
+	if _, _, err = cdrexport.Open(cdrexport.FormatSQLite, path, 0); !errors.Is(err, cdrexport.ErrTimezone) {
+		t.Fatalf("expected ErrTimezone, got %v", err)
+	}
+}
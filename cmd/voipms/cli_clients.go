@@ -0,0 +1,133 @@
+package main
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	voipms "github.com/ticpu/voipms-gorest/v1"
+)
+
+type clientOptions struct {
+	Fields            voipms.ClientFields
+	BalanceManagement int
+	Signup            bool
+	Activate          bool
+}
+
+var clientOpts clientOptions
+
+func newClientCommand() *cobra.Command {
+	clientCmd := &cobra.Command{
+		Use:   "client",
+		Short: "Manage reseller clients",
+		Run:   help,
+	}
+
+	addCmd := &cobra.Command{
+		Use:   "add",
+		Short: "Create a reseller client",
+		Args:  cobra.NoArgs,
+		Run:   addClient,
+	}
+	addClientFlags(addCmd.Flags())
+	addCmd.Flags().BoolVar(&clientOpts.Signup, "signup", false, "Use signupClient, like the reseller signup page, instead of addClient")
+	addCmd.Flags().BoolVar(&clientOpts.Activate, "activate", true, "Activate the client right away when using --signup")
+
+	setCmd := &cobra.Command{
+		Use:   "set CLIENT",
+		Short: "Update a reseller client, unspecified fields are left unchanged",
+		Args:  cobra.ExactArgs(1),
+		Run:   setClient,
+	}
+	addClientFlags(setCmd.Flags())
+
+	clientCmd.AddCommand(addCmd, setCmd)
+	return clientCmd
+}
+
+func addClientFlags(flags *pflag.FlagSet) {
+	fields := &clientOpts.Fields
+	flags.StringVar(&fields.FirstName, "firstname", "", "First name")
+	flags.StringVar(&fields.LastName, "lastname", "", "Last name")
+	flags.StringVar(&fields.Company, "company", "", "Company name")
+	flags.StringVar(&fields.Address, "address", "", "Street address")
+	flags.StringVar(&fields.City, "city", "", "City")
+	flags.StringVar(&fields.State, "state", "", "State or province")
+	flags.StringVar(&fields.Country, "country", "", "Country code")
+	flags.StringVar(&fields.Zip, "zip", "", "Zip or postal code")
+	flags.StringVar(&fields.PhoneNumber, "phone", "", "Phone number, digits only")
+	flags.StringVar(&fields.Email, "email", "", "Email address, used to log in")
+	flags.StringVar(&fields.Password, "password", "", "Portal password")
+	flags.IntVar(&clientOpts.BalanceManagement, "balance-management", 0, "Balance management, 1 for prepaid or 2 for postpaid")
+}
+
+func clientFieldsFromFlags(cmd *cobra.Command, fields voipms.ClientFields) voipms.ClientFields {
+	flags := cmd.Flags()
+	values := map[string]*string{
+		"firstname": &fields.FirstName,
+		"lastname":  &fields.LastName,
+		"company":   &fields.Company,
+		"address":   &fields.Address,
+		"city":      &fields.City,
+		"state":     &fields.State,
+		"country":   &fields.Country,
+		"zip":       &fields.Zip,
+		"phone":     &fields.PhoneNumber,
+		"email":     &fields.Email,
+		"password":  &fields.Password,
+	}
+
+	for name, value := range values {
+		if flags.Changed(name) {
+			*value, _ = flags.GetString(name)
+		}
+	}
+
+	if flags.Changed("balance-management") {
+		balanceManagement := clientOpts.BalanceManagement
+		fields.BalanceManagement = &balanceManagement
+	}
+
+	return fields
+}
+
+func addClient(cmd *cobra.Command, _ []string) {
+	var (
+		err      error
+		response *voipms.AddClientResponse
+	)
+
+	fields := clientFieldsFromFlags(cmd, voipms.ClientFields{})
+
+	if clientOpts.Signup {
+		response, err = vms.SignupClient(fields, clientOpts.Activate)
+	} else {
+		response, err = vms.AddClient(fields)
+	}
+
+	if err != nil {
+		log.Fatalf("error while adding client: %v", err)
+	}
+
+	log.Printf("client %d created", response.Client)
+}
+
+func setClient(cmd *cobra.Command, args []string) {
+	var (
+		err    error
+		client *voipms.ClientInfo
+	)
+
+	if client, err = vms.GetClientOneClient(args[0]); err != nil {
+		log.Fatalf("error while fetching client %s: %v", args[0], err)
+	}
+
+	fields := clientFieldsFromFlags(cmd, voipms.ClientFieldsFromInfo(client))
+
+	if _, err = vms.SetClient(args[0], fields); err != nil {
+		log.Fatalf("error while updating client %s: %v", args[0], err)
+	}
+
+	log.Printf("client %s updated", args[0])
+}
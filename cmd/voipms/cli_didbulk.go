@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	voipms "github.com/ticpu/voipms-gorest/v1"
+)
+
+type didBulkOptions struct {
+	File              string
+	All               bool
+	FilterPop         int
+	FilterRouting     string
+	FilterDescription string
+	Workers           int
+	Rate              float64
+	Report            string
+}
+
+var didBulkOpts didBulkOptions
+
+func newDIDBulkCommand() *cobra.Command {
+	bulkCmd := &cobra.Command{
+		Use:   "bulk OPERATION VALUE [DID...]",
+		Short: "Apply pop HOST|POP, routing TARGET, sms on|off or note TEXT to many DIDs and report the outcome as JSON",
+		Args:  cobra.MinimumNArgs(2),
+		Run:   didBulk,
+	}
+
+	flags := bulkCmd.Flags()
+	flags.StringVarP(&didBulkOpts.File, "file", "f", "", "File listing one DID per line, - for stdin")
+	flags.BoolVar(&didBulkOpts.All, "all", false, "Select every DID on the account")
+	flags.IntVar(&didBulkOpts.FilterPop, "filter-pop", 0, "Only DIDs on this POP")
+	flags.StringVar(&didBulkOpts.FilterRouting, "filter-routing", "", "Only DIDs with this routing")
+	flags.StringVar(&didBulkOpts.FilterDescription, "filter-description", "", "Only DIDs whose description contains this text")
+	flags.IntVar(&didBulkOpts.Workers, "workers", voipms.DefaultBulkWorkers, "DIDs changed at once")
+	flags.Float64Var(&didBulkOpts.Rate, "rate", 2, "Requests per second shared by all workers, 0 for no limit")
+	flags.StringVar(&didBulkOpts.Report, "report", "", "Write the JSON report to this file instead of stdout")
+
+	return bulkCmd
+}
+
+// parseBulkOperation builds the operation named by the first two arguments.
+func parseBulkOperation(name string, value string) *voipms.DIDOperation {
+	switch name {
+	case "pop":
+		if pop, err := strconv.Atoi(value); err == nil {
+			return voipms.BulkSetPop(voipms.VoIpMsStringInt(pop))
+		}
+		return voipms.BulkSetPopByHostname(value)
+	case "routing":
+		return voipms.BulkSetRouting(parseRoutingTarget("routing", value))
+	case "sms":
+		switch value {
+		case "on", "true", "1":
+			return voipms.BulkSetSMS(true)
+		case "off", "false", "0":
+			return voipms.BulkSetSMS(false)
+		}
+		log.Fatalf("invalid sms value %s, expecting on or off", value)
+	case "note":
+		return voipms.BulkSetNote(value)
+	}
+
+	log.Fatalf("unknown operation %s, expecting pop, routing, sms or note", name)
+	return nil
+}
+
+// readDIDList reads one DID per line, skipping blank lines and # comments.
+func readDIDList(name string) []string {
+	var (
+		err    error
+		dids   []string
+		reader io.Reader = os.Stdin
+	)
+
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			log.Fatalf("error while opening %s: %v", name, err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			dids = append(dids, line)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		log.Fatalf("error while reading %s: %v", name, err)
+	}
+
+	return dids
+}
+
+func didBulk(cmd *cobra.Command, args []string) {
+	operation := parseBulkOperation(args[0], args[1])
+	flags := cmd.Flags()
+
+	options := voipms.BulkOptions{
+		DIDs:    args[2:],
+		Workers: didBulkOpts.Workers,
+	}
+
+	if didBulkOpts.File != "" {
+		options.DIDs = append(options.DIDs, readDIDList(didBulkOpts.File)...)
+	}
+
+	var routing voipms.RoutingTarget
+	if didBulkOpts.FilterRouting != "" {
+		routing = parseRoutingTarget("filter-routing", didBulkOpts.FilterRouting)
+	}
+
+	filtered := flags.Changed("filter-pop") || flags.Changed("filter-routing") || flags.Changed("filter-description")
+	if filtered {
+		options.Filter = func(did *voipms.DIDInfo) bool {
+			return (!flags.Changed("filter-pop") || int(did.Pop) == didBulkOpts.FilterPop) &&
+				(routing.IsZero() || did.Routing == routing) &&
+				strings.Contains(did.Description, didBulkOpts.FilterDescription)
+		}
+	}
+
+	// Refuse to touch every DID on the account by accident.
+	if len(options.DIDs) == 0 && !filtered && !didBulkOpts.All {
+		log.Fatalln("no DID selected, list them, use --file, a --filter-* flag or --all")
+	}
+
+	if didBulkOpts.Rate > 0 {
+		options.Limiter = voipms.NewRateLimiter(didBulkOpts.Rate, 1)
+	}
+
+	report, err := vms.BulkUpdateDIDs(operation, options)
+	if err != nil {
+		log.Fatalf("error while running %s: %v", operation.Name, err)
+	}
+
+	writeBulkReport(report)
+
+	log.Printf("%s: %d ok, %d skipped, %d failed", operation.Name, report.OK, report.Skipped, report.Failed)
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func writeBulkReport(report *voipms.BulkReport) {
+	var (
+		err    error
+		output = os.Stdout
+	)
+
+	if didBulkOpts.Report != "" {
+		if output, err = os.Create(didBulkOpts.Report); err != nil {
+			log.Fatalf("error while creating %s: %v", didBulkOpts.Report, err)
+		}
+		defer output.Close()
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(report); err != nil {
+		log.Fatalf("error while writing report: %v", err)
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	voipms "github.com/ticpu/voipms-gorest/v1"
+)
+
+type didSearchOptions struct {
+	Country  string
+	Province string
+	State    string
+	Type     string
+	TollFree bool
+}
+
+type didOrderOptions struct {
+	Routing             string
+	FailoverBusy        string
+	FailoverUnreachable string
+	FailoverNoAnswer    string
+	Voicemail           string
+	Pop                 string
+	Dialtime            int
+	CNAM                bool
+	CallerIDPrefix      string
+	Note                string
+	BillingType         int
+	Account             string
+	Monthly             string
+	Setup               string
+	Minute              string
+	TollFree            bool
+	Virtual             bool
+	Confirm             bool
+}
+
+var (
+	didSearchOpts didSearchOptions
+	didOrderOpts  didOrderOptions
+)
+
+func newDIDSearchCommand() *cobra.Command {
+	searchCmd := &cobra.Command{
+		Use:   "search [QUERY]",
+		Short: "Search DIDs available for ordering; without QUERY list the provinces, states or rate centers",
+		Args:  cobra.RangeArgs(0, 1),
+		Run:   didSearch,
+	}
+
+	flags := searchCmd.Flags()
+	flags.StringVar(&didSearchOpts.Country, "country", "can", "Country searched, can or usa")
+	flags.StringVar(&didSearchOpts.Province, "province", "", "Canadian province, such as QC")
+	flags.StringVar(&didSearchOpts.State, "state", "", "American state, such as NY")
+	flags.StringVar(&didSearchOpts.Type, "type", string(voipms.DIDSearchStarts), "Match QUERY with starts, contains or ends")
+	flags.BoolVar(&didSearchOpts.TollFree, "toll-free", false, "Search toll free numbers")
+
+	return searchCmd
+}
+
+func newDIDOrderCommand() *cobra.Command {
+	orderCmd := &cobra.Command{
+		Use:   "order DID",
+		Short: "Order a DID found with did search; nothing is bought without --confirm",
+		Args:  cobra.ExactArgs(1),
+		Run:   didOrder,
+	}
+
+	flags := orderCmd.Flags()
+	flags.StringVar(&didOrderOpts.Routing, "routing", "", "Routing such as account:100000_office, required")
+	flags.StringVar(&didOrderOpts.FailoverBusy, "failover-busy", "", "Routing when busy")
+	flags.StringVar(&didOrderOpts.FailoverUnreachable, "failover-unreachable", "", "Routing when unreachable")
+	flags.StringVar(&didOrderOpts.FailoverNoAnswer, "failover-noanswer", "", "Routing when not answered")
+	flags.StringVar(&didOrderOpts.Voicemail, "voicemail", "", "Voicemail mailbox")
+	flags.StringVar(&didOrderOpts.Pop, "pop", "", "POP number or server hostname, required")
+	flags.IntVar(&didOrderOpts.Dialtime, "dialtime", 60, "Seconds to ring before failing over")
+	flags.BoolVar(&didOrderOpts.CNAM, "cnam", false, "Look up caller ID names")
+	flags.StringVar(&didOrderOpts.CallerIDPrefix, "callerid-prefix", "", "Prefix added to the caller ID name")
+	flags.StringVar(&didOrderOpts.Note, "note", "", "Note kept with the DID")
+	flags.IntVar(&didOrderOpts.BillingType, "billing-type", 1, "1 for per minute, 2 for flat rate")
+	flags.StringVar(&didOrderOpts.Account, "account", "", "Reseller client the DID is billed to")
+	flags.StringVar(&didOrderOpts.Monthly, "monthly", "", "Monthly fee charged to the reseller client")
+	flags.StringVar(&didOrderOpts.Setup, "setup", "", "Setup fee charged to the reseller client")
+	flags.StringVar(&didOrderOpts.Minute, "minute", "", "Per minute rate charged to the reseller client")
+	flags.BoolVar(&didOrderOpts.TollFree, "toll-free", false, "DID is toll free, implied by an 8xx area code")
+	flags.BoolVar(&didOrderOpts.Virtual, "virtual", false, "Order a virtual DID, DID is then the 3 digits it ends with")
+	flags.BoolVar(&didOrderOpts.Confirm, "confirm", false, "Place the order and charge the account instead of only checking it")
+
+	return orderCmd
+}
+
+func didSearch(_ *cobra.Command, args []string) {
+	country := strings.ToLower(didSearchOpts.Country)
+	if didSearchOpts.State != "" {
+		country = "usa"
+	} else if didSearchOpts.Province != "" {
+		country = "can"
+	}
+
+	if country != "can" && country != "usa" {
+		log.Fatalf("invalid country %s, expecting can or usa", didSearchOpts.Country)
+	}
+
+	if len(args) == 0 {
+		if didSearchOpts.TollFree {
+			log.Fatalln("a query is required to search toll free numbers")
+		}
+		listRegions(country)
+		return
+	}
+
+	search := voipms.DIDSearch{Type: voipms.DIDSearchType(didSearchOpts.Type), Query: args[0]}
+
+	if didSearchOpts.TollFree {
+		dids, err := vms.SearchTollFree(search)
+		if err != nil {
+			log.Fatalf("error while searching toll free numbers: %v", err)
+		}
+		for _, did := range dids.DIDs {
+			fmt.Printf("%s monthly=%s minute=%s setup=%s\n", did.DID, did.Monthly, did.Minute, did.Setup)
+		}
+		return
+	}
+
+	var (
+		err  error
+		dids *voipms.SearchDIDsResponse
+	)
+
+	if country == "usa" {
+		dids, err = vms.SearchDIDsUSA(didSearchOpts.State, search)
+	} else {
+		dids, err = vms.SearchDIDsCAN(didSearchOpts.Province, search)
+	}
+
+	if err != nil {
+		log.Fatalf("error while searching DIDs: %v", err)
+	}
+
+	for _, did := range dids.DIDs {
+		region := did.Province
+		if region == "" {
+			region = did.State
+		}
+		fmt.Printf("%s %s %s per-minute=%s/%s flat=%s sms=%v\n",
+			did.DID, did.RateCenter, region,
+			did.PerMinuteMonthly, did.PerMinuteMinute, did.FlatMonthly, did.SMS != 0)
+	}
+}
+
+// listRegions prints the rate centers of the province or state given, or the
+// provinces or states of country when none is.
+func listRegions(country string) {
+	var (
+		err         error
+		rateCenters *voipms.GetRateCentersResponse
+	)
+
+	switch {
+	case didSearchOpts.Province != "":
+		rateCenters, err = vms.GetRateCentersCAN(didSearchOpts.Province)
+	case didSearchOpts.State != "":
+		rateCenters, err = vms.GetRateCentersUSA(didSearchOpts.State)
+	case country == "usa":
+		states, err := vms.GetStates()
+		if err != nil {
+			log.Fatalf("error while fetching states: %v", err)
+		}
+		for _, state := range states.States {
+			fmt.Printf("%s %s\n", state.State, state.Description)
+		}
+		return
+	default:
+		provinces, err := vms.GetProvinces()
+		if err != nil {
+			log.Fatalf("error while fetching provinces: %v", err)
+		}
+		for _, province := range provinces.Provinces {
+			fmt.Printf("%s %s\n", province.Province, province.Description)
+		}
+		return
+	}
+
+	if err != nil {
+		log.Fatalf("error while fetching rate centers: %v", err)
+	}
+
+	for _, rateCenter := range rateCenters.RateCenters {
+		fmt.Printf("%s available=%s\n", rateCenter.RateCenter, rateCenter.Available)
+	}
+}
+
+// parsePop accepts a POP number or a server hostname such as
+// montreal1.voip.ms.
+func parsePop(text string) int {
+	if pop, err := strconv.Atoi(text); err == nil {
+		return pop
+	}
+
+	server, err := vms.GetServersInfoForPopHostname(text)
+	if err != nil {
+		log.Fatalf("error while looking up POP %s: %v", text, err)
+	}
+
+	return int(server.ServerPOP)
+}
+
+// isTollFree tells whether did has a North American toll free area code.
+func isTollFree(did string) bool {
+	did = strings.TrimPrefix(did, "1")
+	for _, prefix := range []string{"800", "833", "844", "855", "866", "877", "888"} {
+		if strings.HasPrefix(did, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func didOrder(_ *cobra.Command, args []string) {
+	var (
+		err      error
+		response *voipms.OrderDIDResponse
+		did      = args[0]
+	)
+
+	if didOrderOpts.Routing == "" || didOrderOpts.Pop == "" {
+		log.Fatalln("--routing and --pop are both required")
+	}
+
+	order := voipms.DIDOrder{
+		DIDSettings: voipms.DIDSettings{
+			Routing:             parseRoutingTarget("routing", didOrderOpts.Routing),
+			FailoverBusy:        parseRoutingTarget("failover-busy", didOrderOpts.FailoverBusy),
+			FailoverUnreachable: parseRoutingTarget("failover-unreachable", didOrderOpts.FailoverUnreachable),
+			FailoverNoAnswer:    parseRoutingTarget("failover-noanswer", didOrderOpts.FailoverNoAnswer),
+			Voicemail:           didOrderOpts.Voicemail,
+			Pop:                 parsePop(didOrderOpts.Pop),
+			Dialtime:            didOrderOpts.Dialtime,
+			CNAM:                didOrderOpts.CNAM,
+			CallerIDPrefix:      didOrderOpts.CallerIDPrefix,
+			Note:                didOrderOpts.Note,
+			BillingType:         didOrderOpts.BillingType,
+		},
+		Account: didOrderOpts.Account,
+		Test:    !didOrderOpts.Confirm,
+	}
+
+	for _, fee := range []struct {
+		text   string
+		amount *voipms.VoIpMsMoney
+	}{
+		{didOrderOpts.Monthly, &order.Monthly},
+		{didOrderOpts.Setup, &order.Setup},
+		{didOrderOpts.Minute, &order.Minute},
+	} {
+		if fee.text != "" {
+			*fee.amount = parseAmount(fee.text)
+		}
+	}
+
+	switch {
+	case didOrderOpts.Virtual:
+		response, err = vms.OrderDIDVirtual(did, order)
+	case didOrderOpts.TollFree || isTollFree(did):
+		response, err = vms.OrderTollFree(did, order)
+	default:
+		response, err = vms.OrderDID(did, order)
+	}
+
+	if err != nil {
+		log.Fatalf("error while ordering %s: %v", did, err)
+	}
+
+	if !didOrderOpts.Confirm {
+		log.Printf("order for %s is valid, nothing was bought; add --confirm to place it", did)
+		return
+	}
+
+	if response.DID != "" {
+		did = response.DID
+	}
+	log.Printf("%s ordered", did)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+	voipms "github.com/ticpu/voipms-gorest/v1"
+)
+
+var smsSettingsOpts voipms.SMSSettings
+
+type routeOptions struct {
+	FailoverBusy        string
+	FailoverUnreachable string
+	FailoverNoAnswer    string
+	Voicemail           string
+	Dialtime            int
+	Describe            bool
+}
+
+var routeOpts routeOptions
+
+func newDIDCommand() *cobra.Command {
+	didCmd := &cobra.Command{
+		Use:   "did",
+		Short: "Manage DID settings",
+		Run:   help,
+	}
+
+	smsCmd := &cobra.Command{
+		Use:   "sms DID",
+		Short: "Show the SMS settings of a DID, or change the ones given as flags",
+		Args:  cobra.ExactArgs(1),
+		Run:   didSMS,
+	}
+
+	flags := smsCmd.Flags()
+	flags.BoolVar(&smsSettingsOpts.Enabled, "enabled", false, "Enable SMS")
+	flags.BoolVar(&smsSettingsOpts.EmailEnabled, "email-enabled", false, "Send received messages by email")
+	flags.StringVar(&smsSettingsOpts.Email, "email", "", "Email address receiving messages")
+	flags.BoolVar(&smsSettingsOpts.ForwardEnabled, "forward-enabled", false, "Forward received messages to another number")
+	flags.StringVar(&smsSettingsOpts.Forward, "forward", "", "Number receiving forwarded messages")
+	flags.BoolVar(&smsSettingsOpts.URLCallbackEnabled, "url-callback-enabled", false, "Call a URL for every received message")
+	flags.StringVar(&smsSettingsOpts.URLCallback, "url-callback", "", "URL called for every received message")
+	flags.BoolVar(&smsSettingsOpts.URLCallbackRetry, "url-callback-retry", false, "Retry the URL callback until it answers ok")
+	flags.BoolVar(&smsSettingsOpts.SMPPEnabled, "smpp-enabled", false, "Deliver messages over SMPP")
+	flags.StringVar(&smsSettingsOpts.SMPPURL, "smpp-url", "", "SMPP server URL")
+	flags.StringVar(&smsSettingsOpts.SMPPUser, "smpp-user", "", "SMPP username")
+	flags.StringVar(&smsSettingsOpts.SMPPPass, "smpp-pass", "", "SMPP password")
+
+	routeCmd := &cobra.Command{
+		Use:   "route DID [TARGET]",
+		Short: "Show the routing of a DID, or change it to TARGET such as account:100000_office or grp:1234",
+		Args:  cobra.RangeArgs(1, 2),
+		Run:   didRoute,
+	}
+	routeCmd.Flags().StringVar(&routeOpts.FailoverBusy, "failover-busy", "", "Routing when busy, none: to clear")
+	routeCmd.Flags().StringVar(&routeOpts.FailoverUnreachable, "failover-unreachable", "", "Routing when unreachable, none: to clear")
+	routeCmd.Flags().StringVar(&routeOpts.FailoverNoAnswer, "failover-noanswer", "", "Routing when not answered, none: to clear")
+	routeCmd.Flags().StringVar(&routeOpts.Voicemail, "voicemail", "", "Voicemail mailbox")
+	routeCmd.Flags().IntVar(&routeOpts.Dialtime, "dialtime", 0, "Seconds to ring before failing over")
+	routeCmd.Flags().BoolVar(&routeOpts.Describe, "describe", false, "Look up the sub-account, forwarding, ring group or IVR each routing points to")
+
+	didCmd.AddCommand(smsCmd, routeCmd, newDIDSearchCommand(), newDIDOrderCommand(), newDIDBulkCommand())
+
+	return didCmd
+}
+
+func printSMSSettings(did string, settings *voipms.SMSSettings) {
+	fmt.Printf("did: %s\n", did)
+	fmt.Printf("enabled: %v\n", settings.Enabled)
+	fmt.Printf("email: %v %s\n", settings.EmailEnabled, settings.Email)
+	fmt.Printf("forward: %v %s\n", settings.ForwardEnabled, settings.Forward)
+	fmt.Printf("url callback: %v %s retry=%v\n", settings.URLCallbackEnabled, settings.URLCallback, settings.URLCallbackRetry)
+	fmt.Printf("smpp: %v %s user=%s\n", settings.SMPPEnabled, settings.SMPPURL, settings.SMPPUser)
+}
+
+func didSMS(cmd *cobra.Command, args []string) {
+	did, err := vms.GetDidInfo("", args[0])
+	if err != nil {
+		log.Fatalf("error while fetching did info: %v", err)
+	}
+
+	settings := voipms.SMSSettingsFromInfo(did)
+	flags := cmd.Flags()
+	changed := false
+
+	changes := []struct {
+		name  string
+		apply func()
+	}{
+		{"enabled", func() { settings.Enabled = smsSettingsOpts.Enabled }},
+		{"email-enabled", func() { settings.EmailEnabled = smsSettingsOpts.EmailEnabled }},
+		{"email", func() { settings.Email = smsSettingsOpts.Email }},
+		{"forward-enabled", func() { settings.ForwardEnabled = smsSettingsOpts.ForwardEnabled }},
+		{"forward", func() { settings.Forward = smsSettingsOpts.Forward }},
+		{"url-callback-enabled", func() { settings.URLCallbackEnabled = smsSettingsOpts.URLCallbackEnabled }},
+		{"url-callback", func() { settings.URLCallback = smsSettingsOpts.URLCallback }},
+		{"url-callback-retry", func() { settings.URLCallbackRetry = smsSettingsOpts.URLCallbackRetry }},
+		{"smpp-enabled", func() { settings.SMPPEnabled = smsSettingsOpts.SMPPEnabled }},
+		{"smpp-url", func() { settings.SMPPURL = smsSettingsOpts.SMPPURL }},
+		{"smpp-user", func() { settings.SMPPUser = smsSettingsOpts.SMPPUser }},
+		{"smpp-pass", func() { settings.SMPPPass = smsSettingsOpts.SMPPPass }},
+	}
+
+	for _, change := range changes {
+		if flags.Changed(change.name) {
+			change.apply()
+			changed = true
+		}
+	}
+
+	if !changed {
+		printSMSSettings(did.DID, &settings)
+		return
+	}
+
+	if _, err = vms.SetSMS(did.DID, settings); err != nil {
+		log.Fatalf("error while setting SMS settings: %v", err)
+	}
+
+	printSMSSettings(did.DID, &settings)
+}
+
+func parseRoutingTarget(name string, text string) voipms.RoutingTarget {
+	target, err := voipms.ParseRoutingTarget(text)
+	if err == nil && !target.IsZero() {
+		err = target.Validate()
+	}
+	if err != nil {
+		log.Fatalf("invalid %s: %v", name, err)
+	}
+	return target
+}
+
+// describeRouting renders target, followed by what it points to when
+// resolver is set.
+func describeRouting(resolver *voipms.RoutingResolver, target voipms.RoutingTarget) string {
+	if resolver == nil || target.IsZero() {
+		return target.String()
+	}
+
+	resolved, err := resolver.Resolve(context.Background(), target)
+	if err != nil {
+		return fmt.Sprintf("%s - %v", target, err)
+	}
+
+	return fmt.Sprintf("%s - %s", target, resolved)
+}
+
+func printDIDRouting(did *voipms.DIDInfo, resolver *voipms.RoutingResolver) {
+	fmt.Printf("did: %s\n", did.DID)
+	fmt.Printf("routing: %s\n", describeRouting(resolver, did.Routing))
+	fmt.Printf("failover busy: %s\n", describeRouting(resolver, did.FailoverBusy))
+	fmt.Printf("failover unreachable: %s\n", describeRouting(resolver, did.FailoverUnreachable))
+	fmt.Printf("failover no answer: %s\n", describeRouting(resolver, did.FailoverNoAnswer))
+	fmt.Printf("voicemail: %s\n", did.Voicemail)
+	fmt.Printf("dialtime: %d\n", did.Dialtime)
+}
+
+func didRoute(cmd *cobra.Command, args []string) {
+	var (
+		err     error
+		routing voipms.RoutingTarget
+		changed []string
+	)
+
+	flags := cmd.Flags()
+	for _, name := range []string{"failover-busy", "failover-unreachable", "failover-noanswer", "voicemail", "dialtime"} {
+		if flags.Changed(name) {
+			changed = append(changed, name)
+		}
+	}
+
+	// Only the main routing can be changed without resending every setting.
+	fullUpdate := len(changed) > 0
+
+	if len(args) == 2 {
+		routing = parseRoutingTarget("routing", args[1])
+		changed = append([]string{"routing"}, changed...)
+	}
+
+	switch {
+	case fullUpdate:
+		_, err = vms.UpdateDidInfo(args[0], func(settings *voipms.DIDSettings) {
+			if !routing.IsZero() {
+				settings.Routing = routing
+			}
+			if flags.Changed("failover-busy") {
+				settings.FailoverBusy = parseRoutingTarget("failover-busy", routeOpts.FailoverBusy)
+			}
+			if flags.Changed("failover-unreachable") {
+				settings.FailoverUnreachable = parseRoutingTarget("failover-unreachable", routeOpts.FailoverUnreachable)
+			}
+			if flags.Changed("failover-noanswer") {
+				settings.FailoverNoAnswer = parseRoutingTarget("failover-noanswer", routeOpts.FailoverNoAnswer)
+			}
+			if flags.Changed("voicemail") {
+				settings.Voicemail = routeOpts.Voicemail
+			}
+			if flags.Changed("dialtime") {
+				settings.Dialtime = routeOpts.Dialtime
+			}
+		})
+	case !routing.IsZero():
+		_, err = vms.SetDidRouting(args[0], routing)
+	}
+
+	if err != nil {
+		log.Fatalf("error while setting routing of %s: %v", args[0], err)
+	}
+
+	if len(changed) > 0 {
+		log.Printf("%s updated: %s", args[0], strings.Join(changed, ", "))
+	}
+
+	did, err := vms.GetDidInfo("", args[0])
+	if err != nil {
+		log.Fatalf("error while fetching did info: %v", err)
+	}
+
+	var resolver *voipms.RoutingResolver
+	if routeOpts.Describe {
+		resolver = voipms.NewRoutingResolver(vms)
+	}
+
+	printDIDRouting(did, resolver)
+}
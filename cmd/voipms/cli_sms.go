@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	voipms "github.com/ticpu/voipms-gorest/v1"
+	"github.com/ticpu/voipms-gorest/v1/voipmshook"
+)
+
+type smsOptions struct {
+	Media   []string
+	DID     string
+	Contact string
+	From    string
+	To      string
+	Type    string
+	Limit   int
+	MMS     bool
+	Listen  string
+	Path    string
+}
+
+var smsOpts smsOptions
+
+func newSMSCommand() *cobra.Command {
+	smsCmd := &cobra.Command{
+		Use:   "sms",
+		Short: "Send and read SMS and MMS",
+		Run:   help,
+	}
+
+	sendCmd := &cobra.Command{
+		Use:   "send DID DST MESSAGE...",
+		Short: "Send a message, split in several SMS when too long",
+		Args:  cobra.MinimumNArgs(2),
+		Run:   sendSMS,
+	}
+	sendCmd.Flags().StringArrayVar(&smsOpts.Media, "media", nil, "Attach a media URL or data URI, sending an MMS, can be repeated")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List messages, most recent first",
+		Args:  cobra.NoArgs,
+		Run:   listSMS,
+	}
+	listCmd.Flags().StringVar(&smsOpts.DID, "did", "", "Only list messages of this DID")
+	listCmd.Flags().StringVar(&smsOpts.Contact, "contact", "", "Only list messages exchanged with this number")
+	listCmd.Flags().StringVar(&smsOpts.From, "from", "", "First day, as YYYY-MM-DD")
+	listCmd.Flags().StringVar(&smsOpts.To, "to", "", "Last day included, as YYYY-MM-DD")
+	listCmd.Flags().StringVar(&smsOpts.Type, "type", "", "Only list sent or received messages")
+	listCmd.Flags().IntVar(&smsOpts.Limit, "limit", 0, "Maximum number of messages")
+	listCmd.Flags().BoolVar(&smsOpts.MMS, "mms", false, "List MMS instead of SMS")
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Receive SMS URL callbacks and print each message as a JSON line",
+		Args:  cobra.NoArgs,
+		Run:   serveSMS,
+		// The callback receiver does not call the API.
+		PersistentPreRun: func(*cobra.Command, []string) {},
+	}
+	serveCmd.Flags().StringVar(&smsOpts.Listen, "listen", ":8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&smsOpts.Path, "path", "/", "Path of the callback URL")
+
+	smsCmd.AddCommand(
+		sendCmd,
+		listCmd,
+		serveCmd,
+		&cobra.Command{
+			Use:   "delete ID",
+			Short: "Delete a message",
+			Args:  cobra.ExactArgs(1),
+			Run:   deleteSMS,
+		},
+	)
+
+	return smsCmd
+}
+
+func sendSMS(_ *cobra.Command, args []string) {
+	var (
+		err    error
+		result *voipms.SendMessageResult
+	)
+
+	message := strings.Join(args[2:], " ")
+
+	if len(smsOpts.Media) > 0 {
+		result, err = vms.SendMMS(args[0], args[1], message, smsOpts.Media)
+	} else {
+		result, err = vms.SendSMS(args[0], args[1], message)
+	}
+
+	if err != nil {
+		if result != nil && len(result.IDs) > 0 {
+			log.Fatalf("error after sending %d segments %v: %v", len(result.IDs), result.IDs, err)
+		}
+		log.Fatalf("error while sending message: %v", err)
+	}
+
+	log.Printf("sent %d segments to %s: %v", len(result.IDs), args[1], result.IDs)
+}
+
+func listSMS(_ *cobra.Command, _ []string) {
+	var (
+		err      error
+		response *voipms.GetMessagesResponse
+	)
+
+	filter := voipms.MessageFilter{
+		DID:     smsOpts.DID,
+		Contact: smsOpts.Contact,
+		Limit:   smsOpts.Limit,
+	}
+
+	if smsOpts.From != "" {
+		filter.From = parseDate("from", smsOpts.From)
+	}
+
+	if smsOpts.To != "" {
+		filter.To = parseDate("to", smsOpts.To)
+	}
+
+	switch smsOpts.Type {
+	case "":
+	case "sent":
+		messageType := voipms.MessageSent
+		filter.Type = &messageType
+	case "received":
+		messageType := voipms.MessageReceived
+		filter.Type = &messageType
+	default:
+		log.Fatalf("invalid type %s, expecting sent or received", smsOpts.Type)
+	}
+
+	if smsOpts.MMS {
+		response, err = vms.GetMMS(filter)
+	} else {
+		response, err = vms.GetSMS(filter)
+	}
+
+	if err != nil {
+		log.Fatalf("error while fetching messages: %v", err)
+	}
+
+	for _, message := range response.Messages {
+		fmt.Printf("%d %s %s did=%s contact=%s %q", message.ID, message.Date.Format("2006-01-02 15:04:05"),
+			message.Type, message.DID, message.Contact, message.Message)
+		for _, media := range message.Media {
+			fmt.Printf(" %s", media)
+		}
+		fmt.Println()
+	}
+}
+
+func deleteSMS(_ *cobra.Command, args []string) {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		log.Fatalf("invalid message id %s", args[0])
+	}
+
+	if _, err = vms.DeleteSMS(voipms.VoIpMsStringInt(id)); err != nil {
+		log.Fatalf("error while deleting message %s: %v", args[0], err)
+	}
+
+	log.Printf("message %s deleted", args[0])
+}
+
+func serveSMS(_ *cobra.Command, _ []string) {
+	encoder := json.NewEncoder(os.Stdout)
+
+	hook := voipmshook.NewHandler(func(_ context.Context, event *voipmshook.Event) error {
+		return encoder.Encode(event)
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle(smsOpts.Path, hook)
+
+	log.Printf("listening for SMS callbacks on %s%s", smsOpts.Listen, smsOpts.Path)
+	log.Fatal(http.ListenAndServe(smsOpts.Listen, mux))
+}
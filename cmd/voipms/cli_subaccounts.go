@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	voipms "github.com/ticpu/voipms-gorest/v1"
+)
+
+type subAccountOptions struct {
+	Description        string
+	AuthType           int64
+	Password           string
+	IP                 string
+	DeviceType         int64
+	CallerID           string
+	LockInternational  bool
+	InternationalRoute int64
+	CanadaRouting      int64
+	Codecs             string
+	DTMFMode           string
+	NAT                string
+	InternalExtension  string
+	MusicOnHold        string
+	Language           string
+	RecordCalls        bool
+}
+
+var subAccountOpts subAccountOptions
+
+func newSubAccountCommand() *cobra.Command {
+	subAccountCmd := &cobra.Command{
+		Use:   "subaccount",
+		Short: "Manage SIP sub-accounts",
+		Run:   help,
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create USERNAME",
+		Short: "Create a sub-account named after the main account and USERNAME",
+		Args:  cobra.ExactArgs(1),
+		Run:   createSubAccount,
+	}
+	addSubAccountFlags(createCmd.Flags())
+
+	setCmd := &cobra.Command{
+		Use:   "set ACCOUNT",
+		Short: "Update a sub-account, unspecified fields are left unchanged",
+		Args:  cobra.ExactArgs(1),
+		Run:   setSubAccount,
+	}
+	addSubAccountFlags(setCmd.Flags())
+
+	subAccountCmd.AddCommand(
+		&cobra.Command{
+			Use:   "list [ACCOUNT]",
+			Short: "List sub-accounts",
+			Args:  cobra.RangeArgs(0, 1),
+			Run:   listSubAccounts,
+		},
+		createCmd,
+		setCmd,
+		&cobra.Command{
+			Use:   "delete ACCOUNT|ID",
+			Short: "Delete a sub-account",
+			Args:  cobra.ExactArgs(1),
+			Run:   deleteSubAccount,
+		},
+	)
+
+	return subAccountCmd
+}
+
+func addSubAccountFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&subAccountOpts.Description, "description", "", "Description")
+	flags.Int64Var(&subAccountOpts.AuthType, "auth-type", int64(voipms.AuthTypeUserPassword), "Authentication, 1 for user/password or 2 for static IP")
+	flags.StringVar(&subAccountOpts.Password, "password", "", "SIP password")
+	flags.StringVar(&subAccountOpts.IP, "ip", "", "IP address for static IP authentication")
+	flags.Int64Var(&subAccountOpts.DeviceType, "device-type", int64(voipms.DeviceTypePhone), "Device type, 1 for IP PBX or 2 for ATA/IP phone")
+	flags.StringVar(&subAccountOpts.CallerID, "callerid", "", "Caller ID number")
+	flags.BoolVar(&subAccountOpts.LockInternational, "lock-international", true, "Block international calls")
+	flags.Int64Var(&subAccountOpts.InternationalRoute, "international-route", 1, "International route, 1 for value, 2 for premium")
+	flags.Int64Var(&subAccountOpts.CanadaRouting, "canada-routing", 1, "Canada route, 1 for value, 2 for premium")
+	flags.StringVar(&subAccountOpts.Codecs, "codecs", "ulaw;g729", "Allowed codecs separated by semicolons")
+	flags.StringVar(&subAccountOpts.DTMFMode, "dtmf-mode", string(voipms.DTMFModeAuto), "DTMF mode: auto, rfc2833, inband or info")
+	flags.StringVar(&subAccountOpts.NAT, "nat", string(voipms.NATModeYes), "NAT: yes, no, route or never")
+	flags.StringVar(&subAccountOpts.InternalExtension, "internal-extension", "", "Internal extension number")
+	flags.StringVar(&subAccountOpts.MusicOnHold, "music-on-hold", "default", "Music on hold")
+	flags.StringVar(&subAccountOpts.Language, "language", "en", "Language of system prompts")
+	flags.BoolVar(&subAccountOpts.RecordCalls, "record-calls", false, "Record calls")
+}
+
+func boolToInt(value bool) voipms.VoIpMsStringInt {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// applySubAccountFlags copies flags onto account. Only flags set on the
+// command line are applied unless all is true, which is used on creation to
+// pick up the defaults as well.
+func applySubAccountFlags(cmd *cobra.Command, account *voipms.SubAccount, all bool) {
+	flags := cmd.Flags()
+	changed := func(name string) bool {
+		return all || flags.Changed(name)
+	}
+
+	if changed("description") {
+		account.Description = subAccountOpts.Description
+	}
+	if changed("auth-type") {
+		account.AuthType = voipms.AuthType(subAccountOpts.AuthType)
+	}
+	if changed("password") {
+		account.Password = subAccountOpts.Password
+	}
+	if changed("ip") {
+		account.IP = subAccountOpts.IP
+	}
+	if changed("device-type") {
+		account.DeviceType = voipms.DeviceType(subAccountOpts.DeviceType)
+	}
+	if changed("callerid") {
+		account.CallerIDNumber = subAccountOpts.CallerID
+	}
+	if changed("lock-international") {
+		account.LockInternational = boolToInt(subAccountOpts.LockInternational)
+	}
+	if changed("international-route") {
+		account.InternationalRoute = voipms.VoIpMsStringInt(subAccountOpts.InternationalRoute)
+	}
+	if changed("canada-routing") {
+		account.CanadaRouting = voipms.VoIpMsStringInt(subAccountOpts.CanadaRouting)
+	}
+	if changed("codecs") {
+		account.AllowedCodecs = strings.Split(subAccountOpts.Codecs, ";")
+	}
+	if changed("dtmf-mode") {
+		account.DTMFMode = voipms.DTMFMode(subAccountOpts.DTMFMode)
+	}
+	if changed("nat") {
+		account.NAT = voipms.NATMode(subAccountOpts.NAT)
+	}
+	if changed("internal-extension") {
+		account.InternalExtension = subAccountOpts.InternalExtension
+	}
+	if changed("music-on-hold") {
+		account.MusicOnHold = subAccountOpts.MusicOnHold
+	}
+	if changed("language") {
+		account.Language = subAccountOpts.Language
+	}
+	if changed("record-calls") {
+		account.RecordCalls = boolToInt(subAccountOpts.RecordCalls)
+	}
+}
+
+func printSubAccount(account *voipms.SubAccount) {
+	fmt.Printf("%d %s %q auth=%s device=%s callerid=%s ext=%s codecs=%s dtmf=%s nat=%s lock_international=%d\n",
+		account.ID, account.Account, account.Description, account.AuthType, account.DeviceType,
+		account.CallerIDNumber, account.InternalExtension, strings.Join(account.AllowedCodecs, ";"),
+		account.DTMFMode, account.NAT, account.LockInternational)
+}
+
+func listSubAccounts(_ *cobra.Command, args []string) {
+	account := ""
+	if len(args) == 1 {
+		account = args[0]
+	}
+
+	response, err := vms.GetSubAccounts(account)
+	if err != nil {
+		log.Fatalf("error while fetching sub-accounts: %v", err)
+	}
+
+	for i := range response.Accounts {
+		printSubAccount(&response.Accounts[i])
+	}
+}
+
+func createSubAccount(cmd *cobra.Command, args []string) {
+	account := voipms.SubAccount{Username: args[0]}
+	applySubAccountFlags(cmd, &account, true)
+
+	response, err := vms.CreateSubAccount(account)
+	if err != nil {
+		log.Fatalf("error while creating sub-account: %v", err)
+	}
+
+	log.Printf("sub-account %s created with id %d", response.Account, response.ID)
+}
+
+func setSubAccount(cmd *cobra.Command, args []string) {
+	account, err := vms.GetSubAccount(args[0])
+	if err != nil {
+		log.Fatalf("error while fetching sub-account %s: %v", args[0], err)
+	}
+
+	applySubAccountFlags(cmd, account, false)
+
+	if _, err = vms.SetSubAccount(*account); err != nil {
+		log.Fatalf("error while updating sub-account %s: %v", args[0], err)
+	}
+
+	log.Printf("sub-account %s updated", args[0])
+}
+
+func deleteSubAccount(_ *cobra.Command, args []string) {
+	var id voipms.VoIpMsStringInt
+
+	if number, err := strconv.ParseInt(args[0], 10, 64); err == nil {
+		id = voipms.VoIpMsStringInt(number)
+	} else {
+		account, err := vms.GetSubAccount(args[0])
+		if err != nil {
+			log.Fatalf("error while fetching sub-account %s: %v", args[0], err)
+		}
+		id = account.ID
+	}
+
+	if _, err := vms.DelSubAccount(id); err != nil {
+		log.Fatalf("error while deleting sub-account %s: %v", args[0], err)
+	}
+
+	log.Printf("sub-account %s deleted", args[0])
+}
@@ -2,9 +2,4 @@ module github.com/ticpu/voipms-gorest
 
 go 1.20
 
-require github.com/spf13/cobra v1.6.1
-
-require (
-	github.com/inconshreveable/mousetrap v1.0.1 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
-)
+require gopkg.in/yaml.v3 v3.0.1
@@ -1,10 +1,13 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	url2 "net/url"
 	"reflect"
+	"strings"
 )
 
 type GetRegistrationStatus struct {
@@ -14,7 +17,42 @@ type GetRegistrationStatus struct {
 
 type GetClientsRequest struct {
 	BaseRequest
-	Client string `url:"client"`
+	Client string `url:"client,omitempty"`
+}
+
+func (r *GetClientsRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type ClientInfo struct {
+	Client            string          `json:"client"`
+	Email             string          `json:"email"`
+	Password          string          `json:"password"`
+	Company           string          `json:"company"`
+	FirstName         string          `json:"firstname"`
+	LastName          string          `json:"lastname"`
+	Address           string          `json:"address"`
+	City              string          `json:"city"`
+	State             string          `json:"state"`
+	Country           string          `json:"country"`
+	Zip               string          `json:"zip"`
+	PhoneNumber       string          `json:"phone_number"`
+	BalanceManagement VoIpMsStringInt `json:"balance_management"`
+	Balance           VoIpMsMoney     `json:"balance"`
+	Threshold         VoIpMsMoney     `json:"threshold"`
+	BillingType       VoIpMsStringInt `json:"billing_type"`
+	Status            string          `json:"status"`
+}
+
+func (c *ClientInfo) Name() string {
+	return strings.TrimSpace(c.FirstName + " " + c.LastName)
+}
+
+type GetClientsResponse struct {
+	BaseResponse
+	Clients []ClientInfo `json:"clients"`
 }
 
 type RegistrationStatus struct {
@@ -49,6 +87,8 @@ func (r *GetRegistrationStatus) ToURLValues() *url2.Values {
 	return &values
 }
 
+// ParseGetRegistrationStatus decodes a getRegistrationStatus response with
+// its dates read as UTC, unlike GetRegistrationStatus which uses Location.
 func ParseGetRegistrationStatus(data *[]byte) (*GetRegistrationStatusResponse, error) {
 	response := &GetRegistrationStatusResponse{}
 	if err := json.Unmarshal(*data, response); err != nil {
@@ -57,13 +97,21 @@ func ParseGetRegistrationStatus(data *[]byte) (*GetRegistrationStatusResponse, e
 	return response, nil
 }
 
-func (vms *VoIpMsApi) GetClientOneClient(client string) (*BaseResponse, error) {
+func ParseGetClients(data *[]byte) (*GetClientsResponse, error) {
+	response := &GetClientsResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (vms *VoIpMsApi) getClients(ctx context.Context, client string) (*GetClientsResponse, error) {
 	var (
 		err  error
 		data *[]byte
 	)
 
-	data, err = vms.NewHttpRequest(http.MethodGet, "getClients", &GetClientsRequest{
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getClients", &GetClientsRequest{
 		Client: client,
 	})
 
@@ -71,24 +119,61 @@ func (vms *VoIpMsApi) GetClientOneClient(client string) (*BaseResponse, error) {
 		return nil, err
 	}
 
-	return ParseBaseResponse(data)
+	return ParseGetClients(data)
+}
+
+func (vms *VoIpMsApi) GetClientOneClient(client string) (*ClientInfo, error) {
+	return vms.GetClientOneClientContext(context.Background(), client)
+}
+
+func (vms *VoIpMsApi) GetClientOneClientContext(ctx context.Context, client string) (*ClientInfo, error) {
+	var (
+		err     error
+		clients *GetClientsResponse
+	)
+
+	if clients, err = vms.getClients(ctx, client); err != nil {
+		return nil, err
+	}
+
+	for i := range clients.Clients {
+		if clients.Clients[i].Client == client {
+			return &clients.Clients[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("couldn't find client %s", client)
 }
 
-func (vms *VoIpMsApi) GetClients() (*BaseResponse, error) {
-	return vms.GetClientOneClient("")
+func (vms *VoIpMsApi) GetClients() (*GetClientsResponse, error) {
+	return vms.GetClientsContext(context.Background())
+}
+
+func (vms *VoIpMsApi) GetClientsContext(ctx context.Context) (*GetClientsResponse, error) {
+	return vms.getClients(ctx, "")
 }
 
 func (vms *VoIpMsApi) GetRegistrationStatus(account string) (*GetRegistrationStatusResponse, error) {
+	return vms.GetRegistrationStatusContext(context.Background(), account)
+}
+
+func (vms *VoIpMsApi) GetRegistrationStatusContext(ctx context.Context, account string) (*GetRegistrationStatusResponse, error) {
 	var (
-		err  error
-		data *[]byte
+		err      error
+		data     *[]byte
+		response *GetRegistrationStatusResponse
 	)
-	data, err = vms.NewHttpRequest(http.MethodGet, "getRegistrationStatus", &GetRegistrationStatus{
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getRegistrationStatus", &GetRegistrationStatus{
 		Account: account,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return ParseGetRegistrationStatus(data)
+	if response, err = ParseGetRegistrationStatus(data); err != nil {
+		return nil, err
+	}
+
+	vms.localize(response)
+	return response, nil
 }
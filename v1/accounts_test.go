@@ -0,0 +1,189 @@
+package v1_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func TestGetRegistrationStatus(t *testing.T) {
+	fake := newFake(t)
+
+	response, err := fake.Client().GetRegistrationStatus("100000_office")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Registered != "yes" || len(response.Registrations) != 1 {
+		t.Fatalf("unexpected response %+v", response)
+	}
+
+	registration := response.Registrations[0]
+	if registration.Account != "100000_office" || registration.RegisterIP != "192.0.2.10" {
+		t.Fatalf("unexpected registration %+v", registration)
+	}
+
+	if !registration.RegisterNext.Equal(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Fatalf("unexpected register next %v", registration.RegisterNext)
+	}
+}
+
+func TestGetRegistrationStatusNotRegistered(t *testing.T) {
+	fake := newFake(t)
+
+	response, err := fake.Client().GetRegistrationStatus("100000_spare")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Registered != "no" || len(response.Registrations) != 0 {
+		t.Fatalf("unexpected response %+v", response)
+	}
+}
+
+func TestGetRegistrationStatusInvalidAccount(t *testing.T) {
+	fake := newFake(t)
+
+	_, err := fake.Client().GetRegistrationStatus("100000_missing")
+	if !errors.Is(err, v1.ErrInvalidAccount) {
+		t.Fatalf("expected ErrInvalidAccount, got %v", err)
+	}
+}
+
+func TestGetClients(t *testing.T) {
+	fake := newFake(t)
+
+	response, err := fake.Client().GetClients()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Clients) != 2 {
+		t.Fatalf("expected 2 clients, got %d", len(response.Clients))
+	}
+
+	client := response.Clients[0]
+	if client.Name() != "Jane Doe" || client.Company != "Example Inc." || client.Balance != v1.MustParseMoney("12.5") || client.BillingType != 1 {
+		t.Fatalf("unexpected client %+v", client)
+	}
+}
+
+func TestGetClientOneClient(t *testing.T) {
+	fake := newFake(t)
+
+	client, err := fake.Client().GetClientOneClient("12346")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Email != "other@example.com" {
+		t.Fatalf("unexpected client %+v", client)
+	}
+
+	if _, err = fake.Client().GetClientOneClient("99999"); !errors.Is(err, v1.ErrInvalidClient) {
+		t.Fatalf("expected ErrInvalidClient, got %v", err)
+	}
+}
+
+func newClientFields() v1.ClientFields {
+	return v1.ClientFields{
+		FirstName:   "Alice",
+		LastName:    "Martin",
+		Address:     "1 Main St",
+		City:        "Montreal",
+		State:       "QC",
+		Country:     "CA",
+		Zip:         "H2X1Y4",
+		PhoneNumber: "5145550123",
+		Email:       "alice@example.com",
+		Password:    "hunter22",
+	}
+}
+
+func TestAddClient(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	response, err := vms.AddClient(newClientFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := vms.GetClientOneClient(strconv.FormatInt(int64(response.Client), 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Name() != "Alice Martin" || client.City != "Montreal" {
+		t.Fatalf("unexpected client %+v", client)
+	}
+}
+
+func TestAddClientValidation(t *testing.T) {
+	fake := newFake(t)
+
+	fields := newClientFields()
+	fields.Email = "not-an-email"
+
+	var validationError *v1.ValidationError
+	if _, err := fake.Client().AddClient(fields); !errors.As(err, &validationError) || validationError.Field != "email" {
+		t.Fatalf("expected email ValidationError, got %v", err)
+	}
+
+	fields = newClientFields()
+	fields.City = ""
+	if _, err := fake.Client().AddClient(fields); !errors.As(err, &validationError) || validationError.Field != "city" {
+		t.Fatalf("expected city ValidationError, got %v", err)
+	}
+
+	if len(fake.Requests()) != 0 {
+		t.Fatal("invalid requests must not reach the API")
+	}
+}
+
+func TestSignupClient(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	response, err := vms.SignupClient(newClientFields(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := vms.GetClientOneClient(strconv.FormatInt(int64(response.Client), 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Status != "inactive" {
+		t.Fatalf("client should not be activated, got %s", client.Status)
+	}
+}
+
+func TestSetClient(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	fields := newClientFields()
+	fields.Company = "Martin Consulting"
+
+	if _, err := vms.SetClient("12346", fields); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := vms.GetClientOneClient("12346")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Company != "Martin Consulting" || client.Email != "alice@example.com" {
+		t.Fatalf("client not updated %+v", client)
+	}
+
+	if _, err = vms.SetClient("99999", fields); !errors.Is(err, v1.ErrInvalidClient) {
+		t.Fatalf("expected ErrInvalidClient, got %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	url2 "net/url"
+	"reflect"
+)
+
+type GetBalanceRequest struct {
+	BaseRequest
+	Advanced bool `url:"advanced,omitempty"`
+}
+
+func (r *GetBalanceRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type GetTransactionHistoryRequest struct {
+	BaseRequest
+	DateFrom VoIpMsDate `url:"date_from"`
+	DateTo   VoIpMsDate `url:"date_to"`
+}
+
+func (r *GetTransactionHistoryRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+// Balance is the prepaid balance of the main account, reported like the one
+// of a reseller client. Only CurrentBalance is filled unless the advanced
+// breakdown was requested.
+type Balance = ResellerBalance
+
+type GetBalanceResponse struct {
+	BaseResponse
+	Balance Balance `json:"balance"`
+}
+
+// Transaction is a deposit, a charge or a refund posted on the main account.
+type Transaction struct {
+	Date        VoIpMsDateTime `json:"date"`
+	UniqueID    string         `json:"uniqueid"`
+	Type        string         `json:"type"`
+	Description string         `json:"description"`
+	Amount      VoIpMsMoney    `json:"amount"`
+}
+
+// UnmarshalJSON also accepts "ammount", the spelling VoIP.ms uses in
+// getTransactionHistory.
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	type transaction Transaction
+
+	var raw struct {
+		transaction
+		Ammount *VoIpMsMoney `json:"ammount"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*t = Transaction(raw.transaction)
+	if raw.Ammount != nil {
+		t.Amount = *raw.Ammount
+	}
+
+	return nil
+}
+
+type GetTransactionHistoryResponse struct {
+	BaseResponse
+	Transactions []Transaction `json:"transactions"`
+}
+
+func ParseGetBalance(data *[]byte) (*GetBalanceResponse, error) {
+	response := &GetBalanceResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ParseGetTransactionHistory decodes a getTransactionHistory response with
+// its dates read as UTC, unlike GetTransactionHistory which uses Location.
+func ParseGetTransactionHistory(data *[]byte) (*GetTransactionHistoryResponse, error) {
+	response := &GetTransactionHistoryResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetBalance returns the balance of the main account. With advanced set, the
+// amounts spent and the calls made in total and today are included.
+func (vms *VoIpMsApi) GetBalance(advanced bool) (*Balance, error) {
+	return vms.GetBalanceContext(context.Background(), advanced)
+}
+
+func (vms *VoIpMsApi) GetBalanceContext(ctx context.Context, advanced bool) (*Balance, error) {
+	var (
+		err      error
+		data     *[]byte
+		response *GetBalanceResponse
+	)
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getBalance", &GetBalanceRequest{
+		Advanced: advanced,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response, err = ParseGetBalance(data); err != nil {
+		return nil, err
+	}
+
+	return &response.Balance, nil
+}
+
+// validateDateRange checks that both dates are set and in order.
+func validateDateRange(from VoIpMsDate, to VoIpMsDate) error {
+	if from.IsZero() {
+		return &ValidationError{Field: "date_from", Reason: "is required"}
+	}
+
+	if to.IsZero() {
+		return &ValidationError{Field: "date_to", Reason: "is required"}
+	}
+
+	if to.Before(from.Time) {
+		return &ValidationError{Field: "date_to", Reason: "is before date_from"}
+	}
+
+	return nil
+}
+
+// GetTransactionHistory lists the transactions posted between from and to,
+// both days included.
+func (vms *VoIpMsApi) GetTransactionHistory(from VoIpMsDate, to VoIpMsDate) (*GetTransactionHistoryResponse, error) {
+	return vms.GetTransactionHistoryContext(context.Background(), from, to)
+}
+
+func (vms *VoIpMsApi) GetTransactionHistoryContext(ctx context.Context, from VoIpMsDate, to VoIpMsDate) (*GetTransactionHistoryResponse, error) {
+	var (
+		err      error
+		data     *[]byte
+		response *GetTransactionHistoryResponse
+	)
+
+	if err = validateDateRange(from, to); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getTransactionHistory", &GetTransactionHistoryRequest{
+		DateFrom: from,
+		DateTo:   to,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response, err = ParseGetTransactionHistory(data); err != nil {
+		return nil, err
+	}
+
+	vms.localize(response)
+	return response, nil
+}
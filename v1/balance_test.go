@@ -0,0 +1,93 @@
+package v1_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func date(year int, month time.Month, day int) v1.VoIpMsDate {
+	return v1.VoIpMsDate{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestGetBalance(t *testing.T) {
+	fake := newFake(t)
+	fake.SetBalance(v1.Balance{
+		CurrentBalance: v1.MustParseMoney("15.6"),
+		SpentTotal:     v1.MustParseMoney("84.4"),
+		CallsTotal:     1203,
+		TimeTotal:      "41:12:07",
+		SpentToday:     v1.MustParseMoney("0.0425"),
+		CallsToday:     3,
+		TimeToday:      "0:04:15",
+	})
+	vms := fake.Client()
+
+	balance, err := vms.GetBalance(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if balance.CurrentBalance != v1.MustParseMoney("15.60") || balance.CallsToday != 0 {
+		t.Fatalf("unexpected simple balance %+v", balance)
+	}
+
+	if balance, err = vms.GetBalance(true); err != nil {
+		t.Fatal(err)
+	}
+
+	if balance.SpentToday != v1.MustParseMoney("0.0425") || balance.CallsToday != 3 || balance.CallsTotal != 1203 {
+		t.Fatalf("unexpected advanced balance %+v", balance)
+	}
+}
+
+func TestGetTransactionHistory(t *testing.T) {
+	fake := newFake(t)
+	for i, day := range []int{1, 15, 31} {
+		fake.AddTransaction(v1.Transaction{
+			Date:        v1.VoIpMsDateTime{Time: time.Date(2024, time.March, day, 19, 11, 55, 0, time.UTC)},
+			UniqueID:    string(rune('a' + i)),
+			Type:        "Deposit",
+			Description: "Credit card",
+			Amount:      v1.MustParseMoney("25"),
+		})
+	}
+	vms := fake.Client()
+
+	history, err := vms.GetTransactionHistory(date(2024, time.March, 1), date(2024, time.March, 15))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(history.Transactions) != 2 {
+		t.Fatalf("expected 2 transactions, got %+v", history.Transactions)
+	}
+
+	if transaction := history.Transactions[1]; transaction.UniqueID != "b" || transaction.Amount != v1.MustParseMoney("25") {
+		t.Fatalf("unexpected transaction %+v", transaction)
+	}
+
+	params := fake.Requests()[len(fake.Requests())-1]
+	if params.Get("date_from") != "2024-03-01" || params.Get("date_to") != "2024-03-15" {
+		t.Fatalf("unexpected date parameters %v", params)
+	}
+}
+
+func TestGetTransactionHistoryValidation(t *testing.T) {
+	fake := newFake(t)
+
+	var validationError *v1.ValidationError
+	if _, err := fake.Client().GetTransactionHistory(date(2024, time.March, 2), date(2024, time.March, 1)); !errors.As(err, &validationError) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	if _, err := fake.Client().GetTransactionHistory(v1.VoIpMsDate{}, date(2024, time.March, 1)); !errors.As(err, &validationError) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	if len(fake.Requests()) != 0 {
+		t.Fatalf("validation errors should not reach the API")
+	}
+}
@@ -0,0 +1,390 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	url2 "net/url"
+	"reflect"
+)
+
+// ClientRequest is used by every reseller call that only takes a client ID.
+type ClientRequest struct {
+	BaseRequest
+	Client string `url:"client"`
+}
+
+func (r *ClientRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type AddChargeRequest struct {
+	BaseRequest
+	Client      string      `url:"client"`
+	Charge      VoIpMsMoney `url:"charge"`
+	Description string      `url:"description,omitempty"`
+	Test        bool        `url:"test,omitempty"`
+}
+
+func (r *AddChargeRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type AddPaymentRequest struct {
+	BaseRequest
+	Client      string      `url:"client"`
+	Payment     VoIpMsMoney `url:"payment"`
+	Description string      `url:"description,omitempty"`
+	Test        bool        `url:"test,omitempty"`
+}
+
+func (r *AddPaymentRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type SetClientThresholdRequest struct {
+	BaseRequest
+	Client    string      `url:"client"`
+	Threshold VoIpMsMoney `url:"threshold"`
+	Email     string      `url:"email,omitempty"`
+}
+
+func (r *SetClientThresholdRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type ResellerBalance struct {
+	CurrentBalance VoIpMsMoney     `json:"current_balance"`
+	SpentTotal     VoIpMsMoney     `json:"spent_total"`
+	CallsTotal     VoIpMsStringInt `json:"calls_total"`
+	TimeTotal      string          `json:"time_total"`
+	SpentToday     VoIpMsMoney     `json:"spent_today"`
+	CallsToday     VoIpMsStringInt `json:"calls_today"`
+	TimeToday      string          `json:"time_today"`
+}
+
+type GetResellerBalanceResponse struct {
+	BaseResponse
+	Balance ResellerBalance `json:"balance"`
+}
+
+// BillingEntry is a charge or a deposit posted on a reseller client.
+type BillingEntry struct {
+	ID          string      `json:"id"`
+	Date        VoIpMsDate  `json:"date"`
+	Amount      VoIpMsMoney `json:"amount"`
+	Description string      `json:"description"`
+}
+
+type GetChargesResponse struct {
+	BaseResponse
+	Charges []BillingEntry `json:"charges"`
+}
+
+type GetDepositsResponse struct {
+	BaseResponse
+	Deposits []BillingEntry `json:"deposits"`
+}
+
+type ClientPackage struct {
+	Package            string            `json:"package"`
+	Name               string            `json:"name"`
+	MarkupFixed        VoIpMsMoney       `json:"markup_fixed"`
+	MarkupPercentage   VoIpMsStringFloat `json:"markup_percentage"`
+	Pulse              VoIpMsStringInt   `json:"pulse"`
+	InternationalRoute VoIpMsStringInt   `json:"international_route"`
+	CanadaRoute        VoIpMsStringInt   `json:"canada_route"`
+	MonthlyFee         VoIpMsMoney       `json:"monthly_fee"`
+	SetupFee           VoIpMsMoney       `json:"setup_fee"`
+	FreeMinutes        VoIpMsStringInt   `json:"free_minutes"`
+}
+
+type GetClientPackagesResponse struct {
+	BaseResponse
+	Packages []ClientPackage `json:"packages"`
+}
+
+type ClientThreshold struct {
+	Threshold VoIpMsMoney `json:"threshold"`
+	Email     string      `json:"email"`
+}
+
+type GetClientThresholdResponse struct {
+	BaseResponse
+	ThresholdInformation ClientThreshold `json:"threshold_information"`
+}
+
+func ParseGetResellerBalance(data *[]byte) (*GetResellerBalanceResponse, error) {
+	response := &GetResellerBalanceResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ParseGetCharges decodes a getCharges response with its dates read as UTC,
+// unlike GetCharges which uses Location.
+func ParseGetCharges(data *[]byte) (*GetChargesResponse, error) {
+	response := &GetChargesResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ParseGetDeposits decodes a getDeposits response with its dates read as UTC,
+// unlike GetDeposits which uses Location.
+func ParseGetDeposits(data *[]byte) (*GetDepositsResponse, error) {
+	response := &GetDepositsResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func ParseGetClientPackages(data *[]byte) (*GetClientPackagesResponse, error) {
+	response := &GetClientPackagesResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func ParseGetClientThreshold(data *[]byte) (*GetClientThresholdResponse, error) {
+	response := &GetClientThresholdResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func validatePositiveAmount(field string, amount VoIpMsMoney) error {
+	if amount <= 0 {
+		return &ValidationError{Field: field, Reason: "must be greater than zero"}
+	}
+	return nil
+}
+
+func (vms *VoIpMsApi) clientRequest(ctx context.Context, apiMethod string, client string) (*[]byte, error) {
+	if err := requireFields("client", client); err != nil {
+		return nil, err
+	}
+
+	return vms.NewHttpRequestContext(ctx, http.MethodGet, apiMethod, &ClientRequest{
+		Client: client,
+	})
+}
+
+func (vms *VoIpMsApi) GetResellerBalance(client string) (*ResellerBalance, error) {
+	return vms.GetResellerBalanceContext(context.Background(), client)
+}
+
+func (vms *VoIpMsApi) GetResellerBalanceContext(ctx context.Context, client string) (*ResellerBalance, error) {
+	var (
+		err      error
+		data     *[]byte
+		response *GetResellerBalanceResponse
+	)
+
+	if data, err = vms.clientRequest(ctx, "getResellerBalance", client); err != nil {
+		return nil, err
+	}
+
+	if response, err = ParseGetResellerBalance(data); err != nil {
+		return nil, err
+	}
+
+	return &response.Balance, nil
+}
+
+// AddCharge debits a client. With test set, VoIP.ms validates the call
+// without posting the charge.
+func (vms *VoIpMsApi) AddCharge(client string, charge VoIpMsMoney, description string, test bool) (*BaseResponse, error) {
+	return vms.AddChargeContext(context.Background(), client, charge, description, test)
+}
+
+func (vms *VoIpMsApi) AddChargeContext(ctx context.Context, client string, charge VoIpMsMoney, description string, test bool) (*BaseResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if err = requireFields("client", client); err != nil {
+		return nil, err
+	}
+
+	if err = validatePositiveAmount("charge", charge); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "addCharge", &AddChargeRequest{
+		Client:      client,
+		Charge:      charge,
+		Description: description,
+		Test:        test,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBaseResponse(data)
+}
+
+// AddPayment credits a client. With test set, VoIP.ms validates the call
+// without posting the payment.
+func (vms *VoIpMsApi) AddPayment(client string, payment VoIpMsMoney, description string, test bool) (*BaseResponse, error) {
+	return vms.AddPaymentContext(context.Background(), client, payment, description, test)
+}
+
+func (vms *VoIpMsApi) AddPaymentContext(ctx context.Context, client string, payment VoIpMsMoney, description string, test bool) (*BaseResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if err = requireFields("client", client); err != nil {
+		return nil, err
+	}
+
+	if err = validatePositiveAmount("payment", payment); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "addPayment", &AddPaymentRequest{
+		Client:      client,
+		Payment:     payment,
+		Description: description,
+		Test:        test,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBaseResponse(data)
+}
+
+func (vms *VoIpMsApi) GetCharges(client string) (*GetChargesResponse, error) {
+	return vms.GetChargesContext(context.Background(), client)
+}
+
+func (vms *VoIpMsApi) GetChargesContext(ctx context.Context, client string) (*GetChargesResponse, error) {
+	var (
+		err      error
+		data     *[]byte
+		response *GetChargesResponse
+	)
+
+	if data, err = vms.clientRequest(ctx, "getCharges", client); err != nil {
+		return nil, err
+	}
+
+	if response, err = ParseGetCharges(data); err != nil {
+		return nil, err
+	}
+
+	vms.localize(response)
+	return response, nil
+}
+
+func (vms *VoIpMsApi) GetDeposits(client string) (*GetDepositsResponse, error) {
+	return vms.GetDepositsContext(context.Background(), client)
+}
+
+func (vms *VoIpMsApi) GetDepositsContext(ctx context.Context, client string) (*GetDepositsResponse, error) {
+	var (
+		err      error
+		data     *[]byte
+		response *GetDepositsResponse
+	)
+
+	if data, err = vms.clientRequest(ctx, "getDeposits", client); err != nil {
+		return nil, err
+	}
+
+	if response, err = ParseGetDeposits(data); err != nil {
+		return nil, err
+	}
+
+	vms.localize(response)
+	return response, nil
+}
+
+func (vms *VoIpMsApi) GetClientPackages(client string) (*GetClientPackagesResponse, error) {
+	return vms.GetClientPackagesContext(context.Background(), client)
+}
+
+func (vms *VoIpMsApi) GetClientPackagesContext(ctx context.Context, client string) (*GetClientPackagesResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if data, err = vms.clientRequest(ctx, "getClientPackages", client); err != nil {
+		return nil, err
+	}
+
+	return ParseGetClientPackages(data)
+}
+
+func (vms *VoIpMsApi) GetClientThreshold(client string) (*ClientThreshold, error) {
+	return vms.GetClientThresholdContext(context.Background(), client)
+}
+
+func (vms *VoIpMsApi) GetClientThresholdContext(ctx context.Context, client string) (*ClientThreshold, error) {
+	var (
+		err      error
+		data     *[]byte
+		response *GetClientThresholdResponse
+	)
+
+	if data, err = vms.clientRequest(ctx, "getClientThreshold", client); err != nil {
+		return nil, err
+	}
+
+	if response, err = ParseGetClientThreshold(data); err != nil {
+		return nil, err
+	}
+
+	return &response.ThresholdInformation, nil
+}
+
+func (vms *VoIpMsApi) SetClientThreshold(client string, threshold VoIpMsMoney, email string) (*BaseResponse, error) {
+	return vms.SetClientThresholdContext(context.Background(), client, threshold, email)
+}
+
+func (vms *VoIpMsApi) SetClientThresholdContext(ctx context.Context, client string, threshold VoIpMsMoney, email string) (*BaseResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if err = requireFields("client", client); err != nil {
+		return nil, err
+	}
+
+	if err = validatePositiveAmount("threshold", threshold); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "setClientThreshold", &SetClientThresholdRequest{
+		Client:    client,
+		Threshold: threshold,
+		Email:     email,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBaseResponse(data)
+}
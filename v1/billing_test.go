@@ -0,0 +1,166 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"12.5", "12.50"},
+		{"0.00950000", "0.0095"},
+		{"-3", "-3.00"},
+		{".25", "0.25"},
+		{"1000000.12345678", "1000000.12345678"},
+		{"0.10000000000", "0.10"},
+		{"+5", "5.00"},
+		{"92233720368.54775807", "92233720368.54775807"},
+		{"-92233720368.54775807", "-92233720368.54775807"},
+	}
+
+	for _, test := range tests {
+		money, err := v1.ParseMoney(test.text)
+		if err != nil {
+			t.Fatalf("%s: %v", test.text, err)
+		}
+		if money.String() != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.text, test.expected, money)
+		}
+	}
+
+	for _, invalid := range []string{"", "-", "abc", "1.123456789", "1e5", "1.2.3",
+		"--5", "+-5", "-+5", "- 5", "92233720368.54775808", "92233720368.99999999", "92233720369"} {
+		if _, err := v1.ParseMoney(invalid); err == nil {
+			t.Errorf("%q should not parse", invalid)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var values []v1.VoIpMsMoney
+	if err := json.Unmarshal([]byte(`["0.10", 0.20, "0.30000000"]`), &values); err != nil {
+		t.Fatal(err)
+	}
+
+	var sum v1.VoIpMsMoney
+	for _, value := range values {
+		sum += value
+	}
+
+	if sum != v1.MustParseMoney("0.6") {
+		t.Fatalf("expected an exact 0.60, got %s", sum)
+	}
+
+	encoded, err := json.Marshal(sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(encoded) != `"0.60"` {
+		t.Fatalf("unexpected encoding %s", encoded)
+	}
+}
+
+func TestChargesAndPayments(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	if _, err := vms.AddPayment("12345", v1.MustParseMoney("20"), "cheque 1001", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := vms.AddCharge("12345", v1.MustParseMoney("2.35"), "setup", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := vms.AddCharge("12345", v1.MustParseMoney("100"), "dry run", true); err != nil {
+		t.Fatal(err)
+	}
+
+	balance, err := vms.GetResellerBalance("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if balance.CurrentBalance != v1.MustParseMoney("30.15") || balance.SpentTotal != v1.MustParseMoney("2.35") {
+		t.Fatalf("unexpected balance %+v", balance)
+	}
+
+	charges, err := vms.GetCharges("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(charges.Charges) != 1 || charges.Charges[0].Description != "setup" {
+		t.Fatalf("unexpected charges %+v", charges.Charges)
+	}
+
+	deposits, err := vms.GetDeposits("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deposits.Deposits) != 1 || deposits.Deposits[0].Amount != v1.MustParseMoney("20") {
+		t.Fatalf("unexpected deposits %+v", deposits.Deposits)
+	}
+}
+
+func TestChargeValidation(t *testing.T) {
+	fake := newFake(t)
+
+	var validationError *v1.ValidationError
+	if _, err := fake.Client().AddCharge("12345", 0, "", false); !errors.As(err, &validationError) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	if _, err := fake.Client().AddPayment("", v1.MustParseMoney("1"), "", false); !errors.As(err, &validationError) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	if _, err := fake.Client().AddCharge("99999", v1.MustParseMoney("1"), "", false); !errors.Is(err, v1.ErrInvalidClient) {
+		t.Fatalf("expected ErrInvalidClient, got %v", err)
+	}
+}
+
+func TestClientThreshold(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	if _, err := vms.SetClientThreshold("12345", v1.MustParseMoney("7.5"), "billing@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	threshold, err := vms.GetClientThreshold("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if threshold.Threshold != v1.MustParseMoney("7.50") || threshold.Email != "billing@example.com" {
+		t.Fatalf("unexpected threshold %+v", threshold)
+	}
+}
+
+func TestClientPackages(t *testing.T) {
+	fake := newFake(t)
+	fake.AddClientPackage("12345", v1.ClientPackage{
+		Package:    "1",
+		Name:       "Residential",
+		MonthlyFee: v1.MustParseMoney("4.99"),
+		Pulse:      60,
+	})
+
+	packages, err := fake.Client().GetClientPackages("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(packages.Packages) != 1 || packages.Packages[0].MonthlyFee != v1.MustParseMoney("4.99") {
+		t.Fatalf("unexpected packages %+v", packages.Packages)
+	}
+}
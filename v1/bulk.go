@@ -0,0 +1,316 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultBulkWorkers is the number of DIDs changed at once when
+// BulkOptions.Workers is zero.
+const DefaultBulkWorkers = 4
+
+// BulkStatus is the outcome of a bulk operation on one DID.
+type BulkStatus string
+
+const (
+	BulkOK      BulkStatus = "ok"
+	BulkFailed  BulkStatus = "failed"
+	BulkSkipped BulkStatus = "skipped"
+)
+
+// BulkResult is the outcome for one DID. Error explains a failure and Reason
+// why the DID was skipped.
+type BulkResult struct {
+	DID    string     `json:"did"`
+	Status BulkStatus `json:"status"`
+	Error  string     `json:"error,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+}
+
+// BulkReport lists a BulkResult for every DID selected, in the order they
+// were selected.
+type BulkReport struct {
+	Operation string       `json:"operation"`
+	OK        int          `json:"ok"`
+	Failed    int          `json:"failed"`
+	Skipped   int          `json:"skipped"`
+	Results   []BulkResult `json:"results"`
+}
+
+// DIDOperation is a change applied by BulkUpdateDIDs, built with BulkSetPop,
+// BulkSetPopByHostname, BulkSetRouting, BulkSetSMS or BulkSetNote.
+type DIDOperation struct {
+	Name string
+
+	// prepare runs once before any DID is changed.
+	prepare func(ctx context.Context, vms *VoIpMsApi) error
+	// apply changes did and returns a non-empty reason when it was skipped.
+	apply func(ctx context.Context, vms *VoIpMsApi, did *DIDInfo) (string, error)
+}
+
+// BulkOptions selects the DIDs changed by BulkUpdateDIDs. Without DIDs nor
+// Filter every DID on the account is selected. DIDs named in DIDs that
+// Filter excludes are reported as skipped.
+type BulkOptions struct {
+	DIDs    []string
+	Filter  func(did *DIDInfo) bool
+	Workers int
+	// Limiter replaces the Limiter of the client for the run. Either way
+	// every worker waits on the same one.
+	Limiter Limiter
+}
+
+const reasonUnchanged = "already set"
+
+// preparePop checks pop against the server list.
+func preparePop(pop *VoIpMsStringInt, hostname string) func(ctx context.Context, vms *VoIpMsApi) error {
+	return func(ctx context.Context, vms *VoIpMsApi) error {
+		servers, err := vms.GetServersInfoContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, server := range servers.Servers {
+			if (hostname != "" && server.ServerHostname == hostname) || (hostname == "" && server.ServerPOP == *pop) {
+				*pop = server.ServerPOP
+				return nil
+			}
+		}
+
+		if hostname != "" {
+			return fmt.Errorf("couldn't find POP for %s", hostname)
+		}
+		return fmt.Errorf("couldn't find POP %d", *pop)
+	}
+}
+
+func applyPop(pop *VoIpMsStringInt) func(ctx context.Context, vms *VoIpMsApi, did *DIDInfo) (string, error) {
+	return func(ctx context.Context, vms *VoIpMsApi, did *DIDInfo) (string, error) {
+		if did.Pop == *pop {
+			return reasonUnchanged, nil
+		}
+		_, err := vms.SetDidPopContext(ctx, did.DID, *pop)
+		return "", err
+	}
+}
+
+// BulkSetPop moves DIDs to the POP numbered pop.
+func BulkSetPop(pop VoIpMsStringInt) *DIDOperation {
+	return &DIDOperation{
+		Name:    fmt.Sprintf("pop %d", pop),
+		prepare: preparePop(&pop, ""),
+		apply:   applyPop(&pop),
+	}
+}
+
+// BulkSetPopByHostname moves DIDs to the POP of the server named hostname,
+// such as montreal1.voip.ms. The server list is fetched once for the run.
+func BulkSetPopByHostname(hostname string) *DIDOperation {
+	var pop VoIpMsStringInt
+
+	return &DIDOperation{
+		Name:    "pop " + hostname,
+		prepare: preparePop(&pop, hostname),
+		apply:   applyPop(&pop),
+	}
+}
+
+// BulkSetRouting changes the main routing of DIDs.
+func BulkSetRouting(routing RoutingTarget) *DIDOperation {
+	return &DIDOperation{
+		Name: "routing " + routing.String(),
+		prepare: func(context.Context, *VoIpMsApi) error {
+			return routing.Validate()
+		},
+		apply: func(ctx context.Context, vms *VoIpMsApi, did *DIDInfo) (string, error) {
+			if did.Routing == routing {
+				return reasonUnchanged, nil
+			}
+			_, err := vms.SetDidRoutingContext(ctx, did.DID, routing)
+			return "", err
+		},
+	}
+}
+
+// BulkSetSMS enables or disables SMS on DIDs, skipping those where SMS is
+// not available.
+func BulkSetSMS(enabled bool) *DIDOperation {
+	return &DIDOperation{
+		Name: fmt.Sprintf("sms %v", enabled),
+		apply: func(ctx context.Context, vms *VoIpMsApi, did *DIDInfo) (string, error) {
+			if did.SMSAvailable == 0 {
+				return "sms not available", nil
+			}
+			if (did.SMSEnabled != 0) == enabled {
+				return reasonUnchanged, nil
+			}
+
+			settings := SMSSettingsFromInfo(did)
+			settings.Enabled = enabled
+			_, err := vms.SetSMSContext(ctx, did.DID, settings)
+			return "", err
+		},
+	}
+}
+
+// BulkSetNote replaces the note of DIDs, leaving their other settings alone.
+func BulkSetNote(note string) *DIDOperation {
+	return &DIDOperation{
+		Name: "note",
+		apply: func(ctx context.Context, vms *VoIpMsApi, did *DIDInfo) (string, error) {
+			if did.Note == note {
+				return reasonUnchanged, nil
+			}
+
+			settings := DIDSettingsFromInfo(did)
+			settings.Note = note
+			_, err := vms.SetDidInfoContext(ctx, did.DID, settings)
+			return "", err
+		},
+	}
+}
+
+// BulkUpdateDIDs applies operation to the DIDs selected by options using a
+// pool of workers. The DIDs are read with a single GetAllDidInfo call.
+// Failures on single DIDs are recorded in the report; an error is only
+// returned when nothing could be attempted.
+func (vms *VoIpMsApi) BulkUpdateDIDs(operation *DIDOperation, options BulkOptions) (*BulkReport, error) {
+	return vms.BulkUpdateDIDsContext(context.Background(), operation, options)
+}
+
+func (vms *VoIpMsApi) BulkUpdateDIDsContext(ctx context.Context, operation *DIDOperation, options BulkOptions) (*BulkReport, error) {
+	var (
+		err  error
+		dids *GetDidInfoResponse
+	)
+
+	if operation == nil || operation.apply == nil {
+		return nil, &ValidationError{Field: "operation", Reason: "must be built with BulkSetPop, BulkSetRouting, BulkSetSMS or BulkSetNote"}
+	}
+
+	if options.Limiter != nil {
+		client := *vms
+		client.Limiter = options.Limiter
+		vms = &client
+	}
+
+	if operation.prepare != nil {
+		if err = operation.prepare(ctx, vms); err != nil {
+			return nil, err
+		}
+	}
+
+	dids, err = vms.GetAllDidInfoContext(ctx)
+	if errors.Is(err, ErrNoDID) {
+		dids = &GetDidInfoResponse{}
+	} else if err != nil {
+		return nil, err
+	}
+
+	report := &BulkReport{Operation: operation.Name}
+	selected := selectDIDs(dids.DIDs, options, report)
+
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultBulkWorkers
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result := &report.Results[i]
+				reason, err := operation.apply(ctx, vms, selected[i])
+				switch {
+				case err != nil:
+					result.Status, result.Error = BulkFailed, err.Error()
+				case reason != "":
+					result.Status, result.Reason = BulkSkipped, reason
+				default:
+					result.Status = BulkOK
+				}
+			}
+		}()
+	}
+
+	for i, did := range selected {
+		if did == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			report.Results[i].Status, report.Results[i].Error = BulkFailed, ctx.Err().Error()
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, result := range report.Results {
+		switch result.Status {
+		case BulkOK:
+			report.OK++
+		case BulkFailed:
+			report.Failed++
+		case BulkSkipped:
+			report.Skipped++
+		}
+	}
+
+	return report, nil
+}
+
+// selectDIDs adds a result to report for every DID selected by options and
+// returns their info at the same index. It is nil for DIDs missing from the
+// account, which are recorded as failed, and for named DIDs the filter
+// excludes, which are recorded as skipped.
+func selectDIDs(dids []DIDInfo, options BulkOptions, report *BulkReport) []*DIDInfo {
+	var selected []*DIDInfo
+
+	add := func(did string, info *DIDInfo) {
+		result := BulkResult{DID: did}
+		if info == nil {
+			result.Status, result.Error = BulkFailed, "DID not found on the account"
+		}
+		report.Results = append(report.Results, result)
+		selected = append(selected, info)
+	}
+
+	if len(options.DIDs) == 0 {
+		for i := range dids {
+			if options.Filter == nil || options.Filter(&dids[i]) {
+				add(dids[i].DID, &dids[i])
+			}
+		}
+		return selected
+	}
+
+	byDID := make(map[string]*DIDInfo, len(dids))
+	for i := range dids {
+		byDID[dids[i].DID] = &dids[i]
+	}
+
+	seen := map[string]bool{}
+	for _, did := range options.DIDs {
+		if seen[did] {
+			continue
+		}
+		seen[did] = true
+
+		info := byDID[did]
+		if info != nil && options.Filter != nil && !options.Filter(info) {
+			report.Results = append(report.Results, BulkResult{DID: did, Status: BulkSkipped, Reason: "excluded by filter"})
+			selected = append(selected, nil)
+			continue
+		}
+		add(did, info)
+	}
+
+	return selected
+}
@@ -0,0 +1,165 @@
+package v1_test
+
+import (
+	"context"
+	"errors"
+	url2 "net/url"
+	"sync/atomic"
+	"testing"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+type countingLimiter struct {
+	waits int32
+}
+
+func (l *countingLimiter) Wait(context.Context) error {
+	atomic.AddInt32(&l.waits, 1)
+	return nil
+}
+
+func countRequests(requests []url2.Values, method string) int {
+	count := 0
+	for _, params := range requests {
+		if params.Get("method") == method {
+			count++
+		}
+	}
+	return count
+}
+
+func TestBulkSetPopByHostname(t *testing.T) {
+	fake := newFake(t)
+	for _, did := range []string{"5145550101", "5145550102", "5145550103"} {
+		fake.AddDID(v1.DIDInfo{DID: did, Routing: v1.MustParseRoutingTarget("vm:101"), Pop: 8, Dialtime: 60, BillingType: 1})
+	}
+
+	limiter := &countingLimiter{}
+	report, err := fake.Client().BulkUpdateDIDs(v1.BulkSetPopByHostname("toronto1.voip.ms"), v1.BulkOptions{
+		DIDs:    []string{"5145550101", "5145550102", "5145550103", "4385550199", "5145559999", "5145550101"},
+		Workers: 2,
+		Limiter: limiter,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []v1.BulkStatus{v1.BulkOK, v1.BulkOK, v1.BulkOK, v1.BulkSkipped, v1.BulkFailed}
+	if len(report.Results) != len(expected) {
+		t.Fatalf("unexpected results %+v", report.Results)
+	}
+	for i, status := range expected {
+		if report.Results[i].Status != status {
+			t.Errorf("%s: expected %s, got %+v", report.Results[i].DID, status, report.Results[i])
+		}
+	}
+
+	if report.OK != 3 || report.Skipped != 1 || report.Failed != 1 {
+		t.Fatalf("unexpected counts %+v", report)
+	}
+
+	if did, _ := fake.DID("5145550102"); did.Pop != 29 {
+		t.Fatalf("pop not updated, got %d", did.Pop)
+	}
+
+	requests := fake.Requests()
+	if count := countRequests(requests, "getServersInfo"); count != 1 {
+		t.Fatalf("expected a single server list lookup, got %d", count)
+	}
+
+	if int(limiter.waits) != len(requests) {
+		t.Fatalf("expected every request to wait on the limiter, got %d for %d requests", limiter.waits, len(requests))
+	}
+
+	if _, err = fake.Client().BulkUpdateDIDs(v1.BulkSetPopByHostname("nowhere.voip.ms"), v1.BulkOptions{}); err == nil {
+		t.Fatal("expected an error for an unknown hostname")
+	}
+}
+
+func TestBulkSetSMSAndNote(t *testing.T) {
+	fake := newFake(t)
+	fake.AddDID(v1.DIDInfo{DID: "5145550101", Routing: v1.MustParseRoutingTarget("vm:101"), Pop: 8, Dialtime: 60, BillingType: 1, SMSAvailable: 1})
+
+	report, err := fake.Client().BulkUpdateDIDs(v1.BulkSetSMS(true), v1.BulkOptions{
+		Filter: func(did *v1.DIDInfo) bool { return did.Pop == 8 },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.OK != 1 || report.Skipped != 1 || report.Results[0].Reason != "sms not available" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	if did, _ := fake.DID("5145550101"); did.SMSEnabled != 1 {
+		t.Fatal("SMS was not enabled")
+	}
+
+	if report, err = fake.Client().BulkUpdateDIDs(v1.BulkSetNote("migrated"), v1.BulkOptions{DIDs: []string{"5145550100"}}); err != nil || report.OK != 1 {
+		t.Fatalf("unexpected report %+v, %v", report, err)
+	}
+
+	did, _ := fake.DID("5145550100")
+	if did.Note != "migrated" || did.Routing.String() != "account:100000_office" || did.Dialtime != 60 {
+		t.Fatalf("settings were not preserved: %+v", did)
+	}
+}
+
+func TestBulkSetRouting(t *testing.T) {
+	fake := newFake(t)
+
+	report, err := fake.Client().BulkUpdateDIDs(v1.BulkSetRouting(v1.MustParseRoutingTarget("grp:42")), v1.BulkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.OK != 2 || report.Operation != "routing grp:42" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	if _, err = fake.Client().BulkUpdateDIDs(v1.BulkSetRouting(v1.MustParseRoutingTarget("grp:sales")), v1.BulkOptions{}); err == nil {
+		t.Fatal("expected an invalid routing to be rejected before any change")
+	}
+}
+
+func TestBulkFilterExcludesNamedDID(t *testing.T) {
+	fake := newFake(t)
+
+	report, err := fake.Client().BulkUpdateDIDs(v1.BulkSetNote("migrated"), v1.BulkOptions{
+		DIDs:   []string{"5145550100", "4385550199"},
+		Filter: func(did *v1.DIDInfo) bool { return did.Pop == 8 },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Results) != 2 || report.OK != 1 || report.Skipped != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	excluded := report.Results[1]
+	if excluded.DID != "4385550199" || excluded.Status != v1.BulkSkipped || excluded.Reason != "excluded by filter" {
+		t.Fatalf("unexpected result for the excluded DID %+v", excluded)
+	}
+
+	if did, _ := fake.DID("4385550199"); did.Note == "migrated" {
+		t.Fatal("excluded DID was changed")
+	}
+}
+
+func TestBulkInvalidOperation(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	for _, operation := range []*v1.DIDOperation{nil, {Name: "x"}} {
+		var validationError *v1.ValidationError
+		if _, err := vms.BulkUpdateDIDs(operation, v1.BulkOptions{}); !errors.As(err, &validationError) {
+			t.Fatalf("%v: expected a validation error, got %v", operation, err)
+		}
+	}
+
+	if len(fake.Requests()) != 0 {
+		t.Fatalf("requests sent for an invalid operation: %v", fake.Requests())
+	}
+}
@@ -0,0 +1,226 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	url2 "net/url"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// MaxCDRWindowDays is the longest date range, in days, VoIP.ms accepts in a
+// single getCDR call.
+const MaxCDRWindowDays = 92
+
+// CDRFilter selects the call detail records returned by GetCDR. When none of
+// Answered, NoAnswer, Busy and Failed is set, calls of every disposition are
+// returned. CallType, CallBilling and Account take the values listed by
+// getCallTypes, getCallBilling and getCallAccounts, "all" when empty.
+// Dates are expressed in the Location of the client unless Timezone, hours
+// from UTC, is set.
+type CDRFilter struct {
+	From        VoIpMsDate `url:"date_from"`
+	To          VoIpMsDate `url:"date_to"`
+	Timezone    *float64   `url:"timezone"`
+	Answered    bool       `url:"answered,omitempty"`
+	NoAnswer    bool       `url:"noanswer,omitempty"`
+	Busy        bool       `url:"busy,omitempty"`
+	Failed      bool       `url:"failed,omitempty"`
+	CallType    string     `url:"calltype,omitempty"`
+	CallBilling string     `url:"callbilling,omitempty"`
+	Account     string     `url:"account,omitempty"`
+	Client      string     `url:"client,omitempty"`
+
+	// Window is the number of days fetched per call, MaxCDRWindowDays when
+	// zero.
+	Window int `url:"-"`
+}
+
+func (f *CDRFilter) Validate() error {
+	if err := validateDateRange(f.From, f.To); err != nil {
+		return err
+	}
+
+	if f.Timezone != nil && (*f.Timezone < -12 || *f.Timezone > 13) {
+		return &ValidationError{Field: "timezone", Reason: "must be between -12 and 13"}
+	}
+
+	if f.Window < 0 || f.Window > MaxCDRWindowDays {
+		return &ValidationError{Field: "window", Reason: fmt.Sprintf("must be between 1 and %d days", MaxCDRWindowDays)}
+	}
+
+	return nil
+}
+
+type GetCDRRequest struct {
+	BaseRequest
+	CDRFilter
+}
+
+func (r *GetCDRRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type CDR struct {
+	Date            VoIpMsDateTime  `json:"date"`
+	CallerID        string          `json:"callerid"`
+	Destination     string          `json:"destination"`
+	Description     string          `json:"description"`
+	Account         string          `json:"account"`
+	Disposition     string          `json:"disposition"`
+	Duration        string          `json:"duration"`
+	Seconds         VoIpMsStringInt `json:"seconds"`
+	Rate            VoIpMsMoney     `json:"rate"`
+	Total           VoIpMsMoney     `json:"total"`
+	UniqueID        string          `json:"uniqueid"`
+	DestinationType string          `json:"destination_type"`
+}
+
+type GetCDRResponse struct {
+	BaseResponse
+	CDR []CDR `json:"cdr"`
+}
+
+// ParseGetCDR decodes a getCDR response with its dates read as UTC, whatever
+// timezone the records were requested in.
+func ParseGetCDR(data *[]byte) (*GetCDRResponse, error) {
+	response := &GetCDRResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// CDRIterator walks the records of a GetCDR call one window at a time, so
+// only a single window is held in memory. Records are returned in
+// chronological order.
+//
+//	records := vms.GetCDR(filter)
+//	for records.Next() {
+//		cdr := records.CDR()
+//	}
+//	if err := records.Err(); err != nil {
+//	}
+type CDRIterator struct {
+	vms    *VoIpMsApi
+	ctx    context.Context
+	filter CDRFilter
+	next   time.Time
+	page   []CDR
+	index  int
+	err    error
+}
+
+// GetCDR returns an iterator over the records matching filter. Ranges longer
+// than the filter window are split into several calls made as the iterator
+// advances.
+func (vms *VoIpMsApi) GetCDR(filter CDRFilter) *CDRIterator {
+	return vms.GetCDRContext(context.Background(), filter)
+}
+
+func (vms *VoIpMsApi) GetCDRContext(ctx context.Context, filter CDRFilter) *CDRIterator {
+	iterator := &CDRIterator{vms: vms, ctx: ctx, filter: filter, index: -1}
+
+	if iterator.err = filter.Validate(); iterator.err != nil {
+		return iterator
+	}
+
+	if iterator.filter.Window == 0 {
+		iterator.filter.Window = MaxCDRWindowDays
+	}
+
+	if !filter.Answered && !filter.NoAnswer && !filter.Busy && !filter.Failed {
+		iterator.filter.Answered = true
+		iterator.filter.NoAnswer = true
+		iterator.filter.Busy = true
+		iterator.filter.Failed = true
+	}
+
+	iterator.next = filter.From.Time
+	return iterator
+}
+
+// Next advances to the next record, fetching the next window when needed. It
+// returns false once every window was read or on error.
+func (it *CDRIterator) Next() bool {
+	for it.err == nil {
+		if it.index+1 < len(it.page) {
+			it.index++
+			return true
+		}
+
+		if it.next.After(it.filter.To.Time) {
+			return false
+		}
+
+		it.err = it.fetch()
+	}
+
+	return false
+}
+
+// CDR returns the current record. Its date carries the filter time zone.
+func (it *CDRIterator) CDR() *CDR {
+	if it.index < 0 || it.index >= len(it.page) {
+		return nil
+	}
+	return &it.page[it.index]
+}
+
+func (it *CDRIterator) Err() error {
+	return it.err
+}
+
+func (it *CDRIterator) fetch() error {
+	var (
+		err      error
+		data     *[]byte
+		response *GetCDRResponse
+	)
+
+	from := it.next
+	to := from.AddDate(0, 0, it.filter.Window-1)
+	if to.After(it.filter.To.Time) {
+		to = it.filter.To.Time
+	}
+
+	timezone := it.vms.timezone(it.filter.Timezone, from)
+
+	request := &GetCDRRequest{CDRFilter: it.filter}
+	request.From = VoIpMsDate{Time: from}
+	request.To = VoIpMsDate{Time: to}
+	request.Timezone = &timezone
+
+	it.page = nil
+	it.index = -1
+	it.next = to.AddDate(0, 0, 1)
+
+	data, err = it.vms.NewHttpRequestContext(it.ctx, http.MethodGet, "getCDR", request)
+	if errors.Is(err, ErrNoCDR) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if response, err = ParseGetCDR(data); err != nil {
+		return err
+	}
+
+	location := timezoneLocation(timezone)
+	for i := range response.CDR {
+		response.CDR[i].Date.Time = inLocation(response.CDR[i].Date.Time, location)
+	}
+
+	sort.SliceStable(response.CDR, func(i, j int) bool {
+		return response.CDR[i].Date.Before(response.CDR[j].Date.Time)
+	})
+
+	it.page = response.CDR
+	return nil
+}
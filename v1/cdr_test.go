@@ -0,0 +1,168 @@
+package v1_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+	"github.com/ticpu/voipms-gorest/v1/voipmstest"
+)
+
+func addCDR(fake *voipmstest.Server, date time.Time, disposition string, uniqueID string) {
+	fake.AddCDR(v1.CDR{
+		Date:        v1.VoIpMsDateTime{Time: date},
+		CallerID:    `"Jane" <5145550101>`,
+		Destination: "5145550100",
+		Account:     "100000_office",
+		Disposition: disposition,
+		Duration:    "00:01:05",
+		Seconds:     65,
+		Rate:        v1.MustParseMoney("0.0095"),
+		Total:       v1.MustParseMoney("0.0104"),
+		UniqueID:    uniqueID,
+	})
+}
+
+func TestGetCDRSplitsRange(t *testing.T) {
+	fake := newFake(t)
+	addCDR(fake, time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC), "ANSWERED", "1")
+	addCDR(fake, time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC), "NO ANSWER", "2")
+	addCDR(fake, time.Date(2024, time.May, 20, 10, 0, 0, 0, time.UTC), "ANSWERED", "3")
+	addCDR(fake, time.Date(2024, time.July, 18, 23, 59, 59, 0, time.UTC), "BUSY", "4")
+	addCDR(fake, time.Date(2024, time.July, 19, 0, 0, 0, 0, time.UTC), "ANSWERED", "5")
+	addCDR(fake, time.Date(2024, time.February, 1, 10, 0, 0, 0, time.UTC), "FAILED", "6")
+
+	records := fake.Client().GetCDR(v1.CDRFilter{
+		From: date(2024, time.January, 1),
+		To:   date(2024, time.July, 18),
+	})
+
+	var uniqueIDs []string
+	for records.Next() {
+		uniqueIDs = append(uniqueIDs, records.CDR().UniqueID)
+	}
+
+	if err := records.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(uniqueIDs, ",") != "1,6,2,3,4" {
+		t.Fatalf("expected records in chronological order, got %v", uniqueIDs)
+	}
+
+	var windows []string
+	for _, params := range fake.Requests() {
+		if params.Get("method") == "getCDR" {
+			windows = append(windows, params.Get("date_from")+"/"+params.Get("date_to"))
+		}
+	}
+
+	expected := []string{"2024-01-01/2024-04-01", "2024-04-02/2024-07-02", "2024-07-03/2024-07-18"}
+	if len(windows) != len(expected) {
+		t.Fatalf("expected windows %v, got %v", expected, windows)
+	}
+	for i := range expected {
+		if windows[i] != expected[i] {
+			t.Fatalf("expected windows %v, got %v", expected, windows)
+		}
+	}
+}
+
+func TestGetCDRFilters(t *testing.T) {
+	fake := newFake(t)
+	addCDR(fake, time.Date(2024, time.March, 1, 3, 30, 0, 0, time.UTC), "ANSWERED", "1")
+	addCDR(fake, time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC), "FAILED", "2")
+
+	vms := fake.Client()
+	vms.Location = time.FixedZone("EST", -5*60*60)
+
+	records := vms.GetCDR(v1.CDRFilter{
+		From:     date(2024, time.February, 29),
+		To:       date(2024, time.February, 29),
+		Answered: true,
+		Window:   1,
+	})
+
+	if !records.Next() {
+		t.Fatalf("expected a record, got error %v", records.Err())
+	}
+
+	cdr := records.CDR()
+	if cdr.UniqueID != "1" || cdr.Total != v1.MustParseMoney("0.0104") {
+		t.Fatalf("unexpected record %+v", cdr)
+	}
+
+	if !cdr.Date.Equal(time.Date(2024, time.March, 1, 3, 30, 0, 0, time.UTC)) {
+		t.Fatalf("expected the record at 03:30 UTC, got %v", cdr.Date)
+	}
+
+	if records.Next() {
+		t.Fatalf("unexpected record %+v", records.CDR())
+	}
+
+	params := fake.Requests()[len(fake.Requests())-1]
+	if params.Get("answered") != "1" || params.Has("failed") || params.Get("timezone") != "-5" {
+		t.Fatalf("unexpected parameters %v", params)
+	}
+}
+
+func TestGetCDRTimezoneOverridesLocation(t *testing.T) {
+	fake := newFake(t)
+	addCDR(fake, time.Date(2024, time.March, 1, 3, 30, 0, 0, time.UTC), "ANSWERED", "1")
+
+	vms := fake.Client()
+	vms.Location = time.FixedZone("EST", -5*60*60)
+
+	pacific := -8.0
+	records := vms.GetCDR(v1.CDRFilter{
+		From:     date(2024, time.February, 29),
+		To:       date(2024, time.February, 29),
+		Timezone: &pacific,
+	})
+
+	if !records.Next() {
+		t.Fatalf("expected a record, got error %v", records.Err())
+	}
+
+	if cdr := records.CDR(); cdr.Date.Format(time.DateTime) != "2024-02-29 19:30:00" || !cdr.Date.Equal(time.Date(2024, time.March, 1, 3, 30, 0, 0, time.UTC)) {
+		t.Fatalf("expected the record at 19:30 UTC-8, got %v", cdr.Date)
+	}
+
+	if params := fake.Requests()[len(fake.Requests())-1]; params.Get("timezone") != "-8" {
+		t.Fatalf("unexpected timezone %q", params.Get("timezone"))
+	}
+}
+
+func TestGetCDRNoRecords(t *testing.T) {
+	fake := newFake(t)
+
+	records := fake.Client().GetCDR(v1.CDRFilter{From: date(2024, time.January, 1), To: date(2024, time.January, 31)})
+	if records.Next() || records.Err() != nil {
+		t.Fatalf("expected no record and no error, got %v", records.Err())
+	}
+}
+
+func TestGetCDRValidation(t *testing.T) {
+	fake := newFake(t)
+
+	tooFar := 14.0
+
+	var validationError *v1.ValidationError
+	for _, filter := range []v1.CDRFilter{
+		{To: date(2024, time.January, 31)},
+		{From: date(2024, time.January, 31), To: date(2024, time.January, 1)},
+		{From: date(2024, time.January, 1), To: date(2024, time.January, 31), Timezone: &tooFar},
+		{From: date(2024, time.January, 1), To: date(2024, time.January, 31), Window: 93},
+	} {
+		records := fake.Client().GetCDR(filter)
+		if records.Next() || !errors.As(records.Err(), &validationError) {
+			t.Fatalf("expected ValidationError for %+v, got %v", filter, records.Err())
+		}
+	}
+
+	if len(fake.Requests()) != 0 {
+		t.Fatalf("validation errors should not reach the API")
+	}
+}
@@ -0,0 +1,425 @@
+// Package cdrexport writes call detail records to a CSV, JSON lines or SQLite
+// file. Opening a file that already holds records resumes the export: a last
+// line left incomplete by an interrupted run is dropped and the records
+// already in the file are reported by Resume so they are not written twice.
+//
+//	filter.Timezone = &timezone
+//	writer, resume, err := cdrexport.Open(cdrexport.FormatCSV, "calls.csv", timezone)
+//	records := vms.GetCDR(filter)
+//	for records.Next() {
+//		if cdr := records.CDR(); !resume.Exported(cdr) {
+//			err = writer.Write(cdr)
+//		}
+//	}
+//	err = writer.Close()
+//
+// Every record carries the time zone its date is expressed in, hours from
+// UTC, and a file is only resumed in the time zone it was started in. The
+// filter gets that time zone rather than the offset of the client Location,
+// which can change with daylight saving time between two runs.
+//
+// The SQLite format uses the database/sql driver registered as "sqlite",
+// such as modernc.org/sqlite, which the program has to import itself.
+package cdrexport
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatJSONL  = "jsonl"
+	FormatSQLite = "sqlite"
+)
+
+// ErrTimezone is returned by Open when the file was exported in another time
+// zone.
+var ErrTimezone = errors.New("file was exported in another time zone")
+
+// Columns are the CSV header and the SQLite columns, in order.
+var Columns = []string{
+	"date", "uniqueid", "callerid", "destination", "description", "account",
+	"disposition", "duration", "seconds", "rate", "total", "destination_type",
+	"timezone",
+}
+
+type Writer interface {
+	Write(cdr *v1.CDR) error
+	Close() error
+}
+
+// Open opens path in format for appending records whose dates are expressed
+// in timezone, hours from UTC. The Resume returned tells which records the
+// file already holds.
+func Open(format string, path string, timezone float64) (Writer, *Resume, error) {
+	var (
+		err    error
+		writer Writer
+		resume = newResume(timezone)
+	)
+
+	switch format {
+	case FormatCSV:
+		writer, err = openCSV(path, resume)
+	case FormatJSONL:
+		writer, err = openJSONL(path, resume)
+	case FormatSQLite:
+		writer, err = openSQLite(path, resume)
+	default:
+		err = errors.New("format must be csv, jsonl or sqlite")
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return writer, resume, nil
+}
+
+// Resume remembers the last record already in a file so an export can
+// continue where a previous one stopped. Records share a second often
+// enough that the unique IDs seen at that second are kept as well.
+type Resume struct {
+	timezone float64
+	location *time.Location
+	last     time.Time
+	seen     map[string]bool
+}
+
+func newResume(timezone float64) *Resume {
+	return &Resume{
+		timezone: timezone,
+		location: time.FixedZone("", int(timezone*3600)),
+	}
+}
+
+// Last is the date of the last record in the file, zero when it holds none.
+func (r *Resume) Last() time.Time {
+	return r.last
+}
+
+// Exported tells whether cdr is already in the file.
+func (r *Resume) Exported(cdr *v1.CDR) bool {
+	return cdr.Date.Before(r.last) || (cdr.Date.Equal(r.last) && r.seen[cdr.UniqueID])
+}
+
+// add records a line read back from the file, its date decoded as UTC.
+func (r *Resume) add(date v1.VoIpMsDateTime, uniqueID string, timezone float64) error {
+	if timezone != r.timezone {
+		return fmt.Errorf("%w: %v, not %v", ErrTimezone, timezone, r.timezone)
+	}
+
+	t := date.Time
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, r.location)
+
+	switch {
+	case t.After(r.last):
+		r.last = t
+		r.seen = map[string]bool{uniqueID: true}
+	case t.Equal(r.last):
+		r.seen[uniqueID] = true
+	}
+
+	return nil
+}
+
+func (r *Resume) addText(date string, uniqueID string, timezone string) error {
+	var dateTime v1.VoIpMsDateTime
+
+	if err := dateTime.UnmarshalText([]byte(date)); err != nil {
+		return err
+	}
+
+	zone, err := strconv.ParseFloat(timezone, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timezone %q", timezone)
+	}
+
+	return r.add(dateTime, uniqueID, zone)
+}
+
+func record(cdr *v1.CDR, timezone float64) []string {
+	date, _ := cdr.Date.MarshalText()
+
+	return []string{
+		string(date), cdr.UniqueID, cdr.CallerID, cdr.Destination, cdr.Description, cdr.Account,
+		cdr.Disposition, cdr.Duration, fmt.Sprint(int64(cdr.Seconds)), cdr.Rate.String(), cdr.Total.String(),
+		cdr.DestinationType, strconv.FormatFloat(timezone, 'f', -1, 64),
+	}
+}
+
+// openAppend opens path for appending, dropping a last line left incomplete
+// by an interrupted export. The file is left at its start so it can be read
+// back first.
+func openAppend(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	if size := bytes.LastIndexByte(data, '\n') + 1; size != len(data) {
+		if err = file.Truncate(int64(size)); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+type csvWriter struct {
+	file     *os.File
+	writer   *csv.Writer
+	timezone float64
+}
+
+func openCSV(path string, resume *Resume) (Writer, error) {
+	file, err := openAppend(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = len(Columns)
+
+	lines := 0
+	for ; ; lines++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err == nil && lines > 0 {
+			err = resume.addText(row[0], row[1], row[len(row)-1])
+		}
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("cannot resume from %s line %d: %w", path, lines+1, err)
+		}
+	}
+
+	if _, err = file.Seek(0, io.SeekEnd); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	writer := csv.NewWriter(file)
+	if lines == 0 {
+		if err = writer.Write(Columns); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+
+	return &csvWriter{file: file, writer: writer, timezone: resume.timezone}, nil
+}
+
+func (w *csvWriter) Write(cdr *v1.CDR) error {
+	if err := w.writer.Write(record(cdr, w.timezone)); err != nil {
+		return err
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		_ = w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// jsonlRecord is a line of a JSON lines export.
+type jsonlRecord struct {
+	v1.CDR
+	Timezone float64 `json:"timezone"`
+}
+
+type jsonlWriter struct {
+	file     *os.File
+	encoder  *json.Encoder
+	timezone float64
+}
+
+func openJSONL(path string, resume *Resume) (Writer, error) {
+	file, err := openAppend(path)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var record jsonlRecord
+		if err = json.Unmarshal(scanner.Bytes(), &record); err == nil {
+			err = resume.add(record.Date, record.UniqueID, record.Timezone)
+		}
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("cannot resume from %s line %d: %w", path, line, err)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	if _, err = file.Seek(0, io.SeekEnd); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &jsonlWriter{file: file, encoder: json.NewEncoder(file), timezone: resume.timezone}, nil
+}
+
+func (w *jsonlWriter) Write(cdr *v1.CDR) error {
+	return w.encoder.Encode(jsonlRecord{CDR: *cdr, Timezone: w.timezone})
+}
+
+func (w *jsonlWriter) Close() error {
+	return w.file.Close()
+}
+
+// sqliteCommitEvery is the number of records inserted per transaction. An
+// interrupted export loses at most the records of its last transaction,
+// which are fetched again when it is resumed.
+const sqliteCommitEvery = 500
+
+type sqliteWriter struct {
+	db       *sql.DB
+	tx       *sql.Tx
+	pending  int
+	timezone float64
+}
+
+func openSQLite(path string, resume *Resume) (Writer, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS cdr (
+		date TEXT NOT NULL,
+		uniqueid TEXT NOT NULL,
+		callerid TEXT,
+		destination TEXT,
+		description TEXT,
+		account TEXT,
+		disposition TEXT,
+		duration TEXT,
+		seconds INTEGER,
+		rate TEXT,
+		total TEXT,
+		destination_type TEXT,
+		timezone REAL NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS cdr_date ON cdr (date)`)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT date, uniqueid, timezone FROM cdr WHERE date = (SELECT MAX(date) FROM cdr)`)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	for rows.Next() {
+		var (
+			date, uniqueID string
+			timezone       float64
+		)
+		if err = rows.Scan(&date, &uniqueID, &timezone); err == nil {
+			err = resume.addText(date, uniqueID, strconv.FormatFloat(timezone, 'f', -1, 64))
+		}
+		if err != nil {
+			_ = rows.Close()
+			_ = db.Close()
+			return nil, fmt.Errorf("cannot resume from %s: %w", path, err)
+		}
+	}
+
+	if err = rows.Close(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &sqliteWriter{db: db, timezone: resume.timezone}, nil
+}
+
+func (w *sqliteWriter) Write(cdr *v1.CDR) error {
+	var err error
+
+	if w.tx == nil {
+		if w.tx, err = w.db.Begin(); err != nil {
+			return err
+		}
+	}
+
+	fields := record(cdr, w.timezone)
+	values := make([]interface{}, len(fields))
+	for i := range fields {
+		values[i] = fields[i]
+	}
+	values[8] = int64(cdr.Seconds)
+	values[12] = w.timezone
+
+	_, err = w.tx.Exec(`INSERT INTO cdr (date, uniqueid, callerid, destination, description, account,
+		disposition, duration, seconds, rate, total, destination_type, timezone)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		values...)
+	if err != nil {
+		return err
+	}
+
+	if w.pending++; w.pending >= sqliteCommitEvery {
+		return w.commit()
+	}
+
+	return nil
+}
+
+func (w *sqliteWriter) commit() error {
+	if w.tx == nil {
+		return nil
+	}
+
+	err := w.tx.Commit()
+	w.tx = nil
+	w.pending = 0
+	return err
+}
+
+func (w *sqliteWriter) Close() error {
+	if err := w.commit(); err != nil {
+		_ = w.db.Close()
+		return err
+	}
+	return w.db.Close()
+}
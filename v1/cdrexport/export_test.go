@@ -0,0 +1,179 @@
+package cdrexport_test
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+	"github.com/ticpu/voipms-gorest/v1/cdrexport"
+)
+
+var eastern = time.FixedZone("", -5*3600)
+
+// records share their second at the boundary used by the tests so resuming
+// has to tell them apart by unique ID.
+func records() []v1.CDR {
+	at := func(hour, minute, second int) v1.VoIpMsDateTime {
+		return v1.VoIpMsDateTime{Time: time.Date(2024, time.March, 1, hour, minute, second, 0, eastern)}
+	}
+
+	return []v1.CDR{
+		{Date: at(9, 0, 0), UniqueID: "1", Disposition: "ANSWERED", Seconds: 12, Total: v1.MustParseMoney("0.01")},
+		{Date: at(10, 30, 0), UniqueID: "2", Disposition: "ANSWERED"},
+		{Date: at(10, 30, 0), UniqueID: "3", Disposition: "BUSY"},
+		{Date: at(10, 30, 0), UniqueID: "4", Disposition: "NO ANSWER", Description: "line, with \"quotes\""},
+		{Date: at(23, 59, 59), UniqueID: "5", Disposition: "FAILED"},
+	}
+}
+
+func write(t *testing.T, format string, path string, timezone float64, cdrs []v1.CDR) (written int) {
+	t.Helper()
+
+	writer, resume, err := cdrexport.Open(format, path, timezone)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range cdrs {
+		if resume.Exported(&cdrs[i]) {
+			continue
+		}
+		if err = writer.Write(&cdrs[i]); err != nil {
+			t.Fatal(err)
+		}
+		written++
+	}
+
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return written
+}
+
+// uniqueIDs reads back the unique IDs of an export in file order.
+func uniqueIDs(t *testing.T, format string, path string) []string {
+	t.Helper()
+
+	var ids []string
+
+	switch format {
+	case cdrexport.FormatCSV:
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		rows, err := csv.NewReader(file).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(rows[0], cdrexport.Columns) {
+			t.Fatalf("unexpected header %v", rows[0])
+		}
+		for _, row := range rows[1:] {
+			ids = append(ids, row[1])
+		}
+	case cdrexport.FormatJSONL:
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var cdr v1.CDR
+			if err = json.Unmarshal(scanner.Bytes(), &cdr); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, cdr.UniqueID)
+		}
+	}
+
+	return ids
+}
+
+func TestResumeTruncatedExport(t *testing.T) {
+	formats := []struct {
+		format  string
+		partial string
+	}{
+		{cdrexport.FormatCSV, `2024-03-01 10:30:00,4,,,"line, with`},
+		{cdrexport.FormatJSONL, `{"date":"2024-03-01 10:30:00","uniqueid":"4"`},
+	}
+
+	for _, test := range formats {
+		t.Run(test.format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cdr."+test.format)
+			cdrs := records()
+
+			if written := write(t, test.format, path, -5, cdrs[:3]); written != 3 {
+				t.Fatalf("expected 3 records written, got %d", written)
+			}
+
+			file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = file.WriteString(test.partial); err != nil {
+				t.Fatal(err)
+			}
+			_ = file.Close()
+
+			// The export starts again on the day of the last record.
+			if written := write(t, test.format, path, -5, cdrs); written != 2 {
+				t.Fatalf("expected 2 records written on resume, got %d", written)
+			}
+
+			if ids := uniqueIDs(t, test.format, path); !reflect.DeepEqual(ids, []string{"1", "2", "3", "4", "5"}) {
+				t.Fatalf("unexpected records %v", ids)
+			}
+
+			if written := write(t, test.format, path, -5, cdrs); written != 0 {
+				t.Fatalf("expected nothing written on an up to date export, got %d", written)
+			}
+		})
+	}
+}
+
+func TestResumeLast(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cdr.csv")
+	cdrs := records()
+	write(t, cdrexport.FormatCSV, path, -5, cdrs[:2])
+
+	writer, resume, err := cdrexport.Open(cdrexport.FormatCSV, path, -5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	if !resume.Last().Equal(cdrs[1].Date.Time) {
+		t.Fatalf("unexpected last record %v", resume.Last())
+	}
+}
+
+func TestResumeOtherTimezone(t *testing.T) {
+	for _, format := range []string{cdrexport.FormatCSV, cdrexport.FormatJSONL} {
+		path := filepath.Join(t.TempDir(), "cdr."+format)
+		write(t, format, path, -5, records()[:1])
+
+		if _, _, err := cdrexport.Open(format, path, 0); !errors.Is(err, cdrexport.ErrTimezone) {
+			t.Errorf("%s: expected ErrTimezone, got %v", format, err)
+		}
+	}
+}
+
+func TestOpenUnknownFormat(t *testing.T) {
+	if _, _, err := cdrexport.Open("xml", filepath.Join(t.TempDir(), "cdr.xml"), 0); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -18,21 +19,129 @@ const (
 	voipmsDateFormat     = "2006-01-02"
 )
 
+// VoIpMsApi sends every call as a POST form so credentials stay out of URLs.
+// Set UseGet to send them in the query string instead. The HTTP method given
+// to NewHttpRequest only tells whether the call is safe to retry.
 type VoIpMsApi struct {
 	ApiUsername string
 	ApiPassword string
 	ApiUrl      string
-	ApiTimeout  time.Duration
+	// ApiTimeout bounds every call unless HttpClient has its own Timeout.
+	ApiTimeout time.Duration
+	// HttpClient sends the calls, a copy of it with ApiTimeout applied when
+	// its Timeout is zero.
+	HttpClient  *http.Client
+	Middlewares []Middleware
+	Retry       *RetryPolicy
+	Limiter     Limiter
+	UseGet      bool
+	// Location is the time zone VoIP.ms dates are expressed in, UTC when nil.
+	// It must match the time zone configured on the account, which VoIP.ms
+	// does not report. GetCDR, GetSMS and GetMMS ask VoIP.ms for dates in
+	// its offset unless their filter sets a Timezone. The Parse functions
+	// have no client to take it from and always read dates as UTC.
+	Location *time.Location
 }
 
+// location is the time zone of the account.
+func (vms *VoIpMsApi) location() *time.Location {
+	if vms.Location == nil {
+		return time.UTC
+	}
+	return vms.Location
+}
+
+// timezone is the offset from UTC, in hours, sent as the timezone parameter
+// of a call starting on day: override when set, otherwise the offset of
+// Location at the start of that day.
+func (vms *VoIpMsApi) timezone(override *float64, day time.Time) float64 {
+	if override != nil {
+		return *override
+	}
+	_, offset := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, vms.location()).Zone()
+	return float64(offset) / 3600
+}
+
+// timezoneLocation is the fixed zone VoIP.ms expresses dates in when asked
+// for timezone.
+func timezoneLocation(timezone float64) *time.Location {
+	return time.FixedZone("", int(timezone*3600))
+}
+
+// localize moves the dates found in response, decoded as UTC, to the time
+// zone of the account, keeping their wall clock.
+func (vms *VoIpMsApi) localize(response interface{}) {
+	if location := vms.location(); location != time.UTC {
+		localizeDates(reflect.ValueOf(response), location)
+	}
+}
+
+var (
+	dateType     = reflect.TypeOf(VoIpMsDate{})
+	dateTimeType = reflect.TypeOf(VoIpMsDateTime{})
+)
+
+func localizeDates(v reflect.Value, location *time.Location) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			localizeDates(v.Elem(), location)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			localizeDates(v.Index(i), location)
+		}
+	case reflect.Struct:
+		switch v.Type() {
+		case dateType:
+			date := v.Interface().(VoIpMsDate)
+			date.Time = inLocation(date.Time, location)
+			v.Set(reflect.ValueOf(date))
+		case dateTimeType:
+			date := v.Interface().(VoIpMsDateTime)
+			date.Time = inLocation(date.Time, location)
+			v.Set(reflect.ValueOf(date))
+		default:
+			for i := 0; i < v.NumField(); i++ {
+				if v.Field(i).CanSet() {
+					localizeDates(v.Field(i), location)
+				}
+			}
+		}
+	}
+}
+
+// inLocation keeps the wall clock of t and moves it to location.
+func inLocation(t time.Time, location *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), location)
+}
+
+const (
+	voipmsZeroDateTime = "0000-00-00 00:00:00"
+	voipmsZeroDate     = "0000-00-00"
+)
+
+// VoIpMsDateTime is written as text in the VoIP.ms format, with the wall
+// clock of its own time zone, and read from text as UTC; the client moves the
+// dates it returns to its Location. JSON carries the offset in RFC 3339 so a
+// date in any time zone decodes to the same instant, and either form is read.
 type VoIpMsDateTime struct {
 	time.Time
 }
 
-func (vmsDateTime *VoIpMsDateTime) UnmarshalJSON(b []byte) (err error) {
-	s := string(b)
-	s = strings.Trim(s, "\"")
-	if s == "0000-00-00 00:00:00" {
+func (vmsDateTime VoIpMsDateTime) MarshalText() ([]byte, error) {
+	if vmsDateTime.IsZero() {
+		return []byte(voipmsZeroDateTime), nil
+	}
+	return []byte(vmsDateTime.Format(voipmsDateTimeFormat)), nil
+}
+
+func (vmsDateTime *VoIpMsDateTime) UnmarshalText(text []byte) (err error) {
+	s := string(text)
+	if s == voipmsZeroDateTime || s == "" {
 		vmsDateTime.Time = time.Time{}
 		return nil
 	}
@@ -40,14 +149,38 @@ func (vmsDateTime *VoIpMsDateTime) UnmarshalJSON(b []byte) (err error) {
 	return
 }
 
-type VoIpMsDate struct {
-	time.Time
+func (vmsDateTime VoIpMsDateTime) MarshalJSON() ([]byte, error) {
+	if vmsDateTime.IsZero() {
+		return json.Marshal(voipmsZeroDateTime)
+	}
+	return json.Marshal(vmsDateTime.Format(time.RFC3339Nano))
 }
 
-func (vmsDate *VoIpMsDate) UnmarshalJSON(b []byte) (err error) {
+func (vmsDateTime *VoIpMsDateTime) UnmarshalJSON(b []byte) (err error) {
 	s := string(b)
 	s = strings.Trim(s, "\"")
-	if s == "0000-00-00" {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		vmsDateTime.Time = t
+		return nil
+	}
+	return vmsDateTime.UnmarshalText([]byte(s))
+}
+
+// VoIpMsDate is a calendar day, marshalled like VoIpMsDateTime.
+type VoIpMsDate struct {
+	time.Time
+}
+
+func (vmsDate VoIpMsDate) MarshalText() ([]byte, error) {
+	if vmsDate.IsZero() {
+		return []byte(voipmsZeroDate), nil
+	}
+	return []byte(vmsDate.Format(voipmsDateFormat)), nil
+}
+
+func (vmsDate *VoIpMsDate) UnmarshalText(text []byte) (err error) {
+	s := string(text)
+	if s == voipmsZeroDate || s == "" {
 		vmsDate.Time = time.Time{}
 		return nil
 	}
@@ -55,22 +188,56 @@ func (vmsDate *VoIpMsDate) UnmarshalJSON(b []byte) (err error) {
 	return
 }
 
+func (vmsDate VoIpMsDate) MarshalJSON() ([]byte, error) {
+	if vmsDate.IsZero() {
+		return json.Marshal(voipmsZeroDate)
+	}
+	return json.Marshal(vmsDate.Format(time.RFC3339Nano))
+}
+
+func (vmsDate *VoIpMsDate) UnmarshalJSON(b []byte) (err error) {
+	s := string(b)
+	s = strings.Trim(s, "\"")
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		vmsDate.Time = t
+		return nil
+	}
+	return vmsDate.UnmarshalText([]byte(s))
+}
+
 type VoIpMsStringBool bool
 
+func (value VoIpMsStringBool) MarshalText() ([]byte, error) {
+	if value {
+		return []byte("Yes"), nil
+	}
+	return []byte("No"), nil
+}
+
+func (valueRef *VoIpMsStringBool) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "Yes":
+		*valueRef = true
+	case "No":
+		*valueRef = false
+	default:
+		return fmt.Errorf("value for bool was %s, expecting Yes or No", text)
+	}
+	return nil
+}
+
+func (value VoIpMsStringBool) MarshalJSON() ([]byte, error) {
+	text, _ := value.MarshalText()
+	return json.Marshal(string(text))
+}
+
 func (valueRef *VoIpMsStringBool) UnmarshalJSON(data []byte) error {
 	var boolString string
 
 	err := json.Unmarshal(data, &boolString)
 
 	if err == nil {
-		if boolString == "Yes" {
-			*valueRef = true
-		} else if boolString == "No" {
-			*valueRef = false
-		} else {
-			return fmt.Errorf("value for bool was %v, expecting Yes or No", boolString)
-		}
-		return nil
+		return valueRef.UnmarshalText([]byte(boolString))
 	}
 
 	var value bool
@@ -84,6 +251,24 @@ func (valueRef *VoIpMsStringBool) UnmarshalJSON(data []byte) error {
 
 type VoIpMsStringInt int64
 
+func (value VoIpMsStringInt) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(value), 10)), nil
+}
+
+func (valueRef *VoIpMsStringInt) UnmarshalText(text []byte) error {
+	i, err := strconv.ParseInt(string(text), 10, 64)
+	if err != nil {
+		return err
+	}
+	*valueRef = VoIpMsStringInt(i)
+	return nil
+}
+
+func (value VoIpMsStringInt) MarshalJSON() ([]byte, error) {
+	text, _ := value.MarshalText()
+	return json.Marshal(string(text))
+}
+
 func (valueRef *VoIpMsStringInt) UnmarshalJSON(data []byte) error {
 	var (
 		intString string
@@ -94,9 +279,7 @@ func (valueRef *VoIpMsStringInt) UnmarshalJSON(data []byte) error {
 	err = json.Unmarshal(data, &intString)
 
 	if err == nil {
-		i, err = strconv.ParseInt(intString, 10, 64)
-		if err == nil {
-			*valueRef = VoIpMsStringInt(i)
+		if err = valueRef.UnmarshalText([]byte(intString)); err == nil {
 			return nil
 		}
 	}
@@ -111,6 +294,24 @@ func (valueRef *VoIpMsStringInt) UnmarshalJSON(data []byte) error {
 
 type VoIpMsStringFloat float64
 
+func (value VoIpMsStringFloat) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatFloat(float64(value), 'f', -1, 64)), nil
+}
+
+func (valueRef *VoIpMsStringFloat) UnmarshalText(text []byte) error {
+	f, err := strconv.ParseFloat(string(text), 64)
+	if err != nil {
+		return err
+	}
+	*valueRef = VoIpMsStringFloat(f)
+	return nil
+}
+
+func (value VoIpMsStringFloat) MarshalJSON() ([]byte, error) {
+	text, _ := value.MarshalText()
+	return json.Marshal(string(text))
+}
+
 func (valueRef *VoIpMsStringFloat) UnmarshalJSON(data []byte) error {
 	var (
 		floatString string
@@ -121,9 +322,7 @@ func (valueRef *VoIpMsStringFloat) UnmarshalJSON(data []byte) error {
 	err = json.Unmarshal(data, &floatString)
 
 	if err == nil {
-		f, err = strconv.ParseFloat(floatString, 64)
-		if err == nil {
-			*valueRef = VoIpMsStringFloat(f)
+		if err = valueRef.UnmarshalText([]byte(floatString)); err == nil {
 			return nil
 		}
 	}
@@ -136,30 +335,6 @@ func (valueRef *VoIpMsStringFloat) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func toURLValues(v reflect.Value) url2.Values {
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-
-	values := url2.Values{}
-
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Type().Field(i)
-		tag := field.Tag.Get("url")
-
-		if tag != "" {
-			values.Add(tag, fmt.Sprintf("%v", v.Field(i).Interface()))
-		} else if field.Anonymous {
-			embeddedValues := toURLValues(v.Field(i))
-			for k, v := range embeddedValues {
-				values[k] = v
-			}
-		}
-	}
-
-	return values
-}
-
 type RequestParams interface {
 	ToURLValues() *url2.Values
 	SetApiUser(username string)
@@ -171,6 +346,9 @@ type BaseRequest struct {
 	ApiUser     string `url:"api_username"`
 	ApiPassword string `url:"api_password"`
 	Method      string `url:"method"`
+
+	// location is the time zone dates are sent in, set by the client.
+	location *time.Location
 }
 
 func (r *BaseRequest) SetApiUser(username string) {
@@ -185,6 +363,14 @@ func (r *BaseRequest) SetApiMethod(method string) {
 	r.Method = method
 }
 
+func (r *BaseRequest) setLocation(location *time.Location) {
+	r.location = location
+}
+
+func (r *BaseRequest) urlLocation() *time.Location {
+	return r.location
+}
+
 func (r *BaseRequest) ToURLValues() *url2.Values {
 	values := url2.Values{}
 	values = toURLValues(reflect.ValueOf(r))
@@ -215,7 +401,7 @@ func NewVoIpMsClient(username string, password string) *VoIpMsApi {
 	}
 }
 
-func (vms *VoIpMsApi) _newHttpRequest(httpMethod string, apiMethod string, requestData RequestParams) (*[]byte, error) {
+func (vms *VoIpMsApi) _newHttpRequest(ctx context.Context, httpMethod string, apiMethod string, requestData RequestParams) (*[]byte, error) {
 	var (
 		err          error
 		url          *url2.URL
@@ -226,34 +412,37 @@ func (vms *VoIpMsApi) _newHttpRequest(httpMethod string, apiMethod string, reque
 		httpClient   *http.Client
 	)
 
-	httpClient = &http.Client{
-		Timeout: vms.ApiTimeout,
-	}
+	httpClient = vms.httpClient()
 
 	requestData.SetApiUser(vms.ApiUsername)
 	requestData.SetApiPassword(vms.ApiPassword)
 	requestData.SetApiMethod(apiMethod)
-
-	queryParameters := requestData.ToURLValues().Encode()
-	if url, err = url2.Parse(fmt.Sprintf("%s?%s", vms.ApiUrl, queryParameters)); err != nil {
-		return nil, err
+	if request, ok := requestData.(interface{ setLocation(*time.Location) }); ok {
+		request.setLocation(vms.location())
 	}
 
+	parameters := requestData.ToURLValues().Encode()
+
 	headers = http.Header{
 		"Accept": []string{"text/json"},
 	}
 
-	request = &http.Request{
-		Method:        httpMethod,
-		URL:           url,
-		Body:          nil,
-		Header:        headers,
-		GetBody:       nil,
-		ContentLength: 0,
-		Close:         true,
-		Form:          nil,
-		PostForm:      nil,
-		Response:      nil,
+	if vms.UseGet {
+		if url, err = url2.Parse(fmt.Sprintf("%s?%s", vms.ApiUrl, parameters)); err != nil {
+			return nil, err
+		}
+		request, err = http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+	} else {
+		request, err = http.NewRequestWithContext(ctx, http.MethodPost, vms.ApiUrl, strings.NewReader(parameters))
+		headers.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range headers {
+		request.Header[key] = values
 	}
 
 	if response, err = httpClient.Do(request); err != nil {
@@ -261,6 +450,10 @@ func (vms *VoIpMsApi) _newHttpRequest(httpMethod string, apiMethod string, reque
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode >= http.StatusBadRequest {
+		return nil, &HTTPError{StatusCode: response.StatusCode, Status: response.Status}
+	}
+
 	if responseBody, err = io.ReadAll(response.Body); err != nil {
 		return nil, err
 	}
@@ -269,11 +462,36 @@ func (vms *VoIpMsApi) _newHttpRequest(httpMethod string, apiMethod string, reque
 }
 
 func (vms *VoIpMsApi) NewHttpRequest(httpMethod string, apiMethod string, requestData RequestParams) (*[]byte, error) {
-	data, err := vms._newHttpRequest(httpMethod, apiMethod, requestData)
+	return vms.NewHttpRequestContext(context.Background(), httpMethod, apiMethod, requestData)
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("%s", strings.Replace(err.Error(), vms.ApiPassword, "[REDACTED]", -1))
+func (vms *VoIpMsApi) NewHttpRequestContext(ctx context.Context, httpMethod string, apiMethod string, requestData RequestParams) (*[]byte, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	for attempt := 1; ; attempt++ {
+		if vms.Limiter != nil {
+			if err = vms.Limiter.Wait(ctx); err != nil {
+				return nil, vms.redactError(err)
+			}
+		}
+
+		if data, err = vms._newHttpRequest(ctx, httpMethod, apiMethod, requestData); err == nil {
+			if err = checkResponseStatus(apiMethod, data); err == nil {
+				return data, nil
+			}
+		}
+
+		if !vms.Retry.shouldRetry(ctx, attempt, httpMethod, apiMethod, err) {
+			break
+		}
+
+		if err = vms.Retry.wait(ctx, attempt); err != nil {
+			break
+		}
 	}
 
-	return data, err
+	return nil, vms.redactError(err)
 }
@@ -0,0 +1,332 @@
+package v1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+	"github.com/ticpu/voipms-gorest/v1/voipmstest"
+)
+
+const (
+	testUsername = "user@example.com"
+	testPassword = "s3cr3t-api-key"
+)
+
+func newFake(t *testing.T) *voipmstest.Server {
+	t.Helper()
+
+	fake := voipmstest.NewServer(testUsername, testPassword)
+	t.Cleanup(fake.Close)
+
+	fake.AddServer(v1.ServerInfo{
+		ServerName:            "Montreal1",
+		ServerShortname:       "Montreal",
+		ServerHostname:        "montreal1.voip.ms",
+		ServerIP:              "208.100.60.8",
+		ServerCountry:         "Canada",
+		ServerPOP:             8,
+		ServerRecommendedText: "yes",
+	})
+	fake.AddServer(v1.ServerInfo{
+		ServerName:            "Toronto1",
+		ServerShortname:       "Toronto",
+		ServerHostname:        "toronto1.voip.ms",
+		ServerIP:              "184.75.215.146",
+		ServerCountry:         "Canada",
+		ServerPOP:             29,
+		ServerRecommendedText: "no",
+	})
+
+	fake.AddDID(v1.DIDInfo{
+		DID:         "5145550100",
+		Description: "Main line",
+		Routing:     v1.MustParseRoutingTarget("account:100000_office"),
+		Pop:         8,
+		Dialtime:    60,
+		BillingType: 1,
+		Note:        "front desk",
+		OrderDate:   v1.VoIpMsDateTime{Time: time.Date(2021, 3, 4, 10, 11, 12, 0, time.UTC)},
+		NextBilling: v1.VoIpMsDate{Time: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+	})
+	fake.AddClientDID("12345", v1.DIDInfo{
+		DID:            "4385550199",
+		Description:    "Reseller line",
+		Routing:        v1.MustParseRoutingTarget("fwd:1234"),
+		Pop:            29,
+		ResellerMinute: 0.01,
+	})
+
+	fake.AddRegistration("100000_office", v1.RegistrationStatus{
+		ServerName:        "Montreal1",
+		ServerHostname:    "montreal1.voip.ms",
+		ServerPOP:         "8",
+		RegisterIP:        "192.0.2.10",
+		RegisterPort:      "5060",
+		RegisterNext:      v1.VoIpMsDateTime{Time: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)},
+		RegisterTransport: "UDP",
+	})
+	fake.AddAccount("100000_spare")
+
+	fake.AddClient(v1.ClientInfo{
+		Client:      "12345",
+		Email:       "client@example.com",
+		Company:     "Example Inc.",
+		FirstName:   "Jane",
+		LastName:    "Doe",
+		Balance:     v1.MustParseMoney("12.50"),
+		Threshold:   v1.MustParseMoney("5"),
+		BillingType: 1,
+		Status:      "active",
+	})
+	fake.AddClient(v1.ClientInfo{
+		Client:    "12346",
+		Email:     "other@example.com",
+		FirstName: "John",
+		LastName:  "Smith",
+	})
+
+	return fake
+}
+
+func TestTransportErrorIsRedacted(t *testing.T) {
+	vms := &v1.VoIpMsApi{
+		ApiUsername: testUsername,
+		ApiPassword: testPassword,
+		ApiUrl:      "http://127.0.0.1:1/rest.php",
+	}
+
+	for _, useGet := range []bool{false, true} {
+		vms.UseGet = useGet
+
+		_, err := vms.GetServersInfo()
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+
+		if strings.Contains(err.Error(), testPassword) || strings.Contains(err.Error(), "user%40example.com") {
+			t.Fatalf("credentials leaked in error: %v", err)
+		}
+	}
+}
+
+func TestCredentialsSentInPostBody(t *testing.T) {
+	var requests []*http.Request
+
+	fake := newFake(t)
+	vms := fake.Client()
+	vms.Use(func(next http.RoundTripper) http.RoundTripper {
+		return v1.RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			requests = append(requests, request)
+			return next.RoundTrip(request)
+		})
+	})
+
+	if _, err := vms.GetRegistrationStatus("100000_office"); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests[0].Method != http.MethodPost || requests[0].URL.RawQuery != "" {
+		t.Fatalf("expected a POST without query string, got %s %s", requests[0].Method, requests[0].URL)
+	}
+
+	vms.UseGet = true
+	if _, err := vms.GetRegistrationStatus("100000_office"); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests[1].Method != http.MethodGet || requests[1].URL.Query().Get("account") != "100000_office" {
+		t.Fatalf("expected a GET with query string, got %s %s", requests[1].Method, requests[1].URL)
+	}
+}
+
+func TestDumpIsRedacted(t *testing.T) {
+	var dump bytes.Buffer
+
+	fake := newFake(t)
+	vms := fake.Client()
+	vms.UseGet = true
+	vms.Use(v1.WithDump(&dump))
+
+	if _, err := vms.GetServersInfo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(dump.String(), "getServersInfo") {
+		t.Fatalf("dump does not contain the request:\n%s", dump.String())
+	}
+
+	if strings.Contains(dump.String(), testPassword) || strings.Contains(dump.String(), "user%40example.com") {
+		t.Fatalf("credentials leaked in dump:\n%s", dump.String())
+	}
+}
+
+func TestRedact(t *testing.T) {
+	vms := v1.NewVoIpMsClient(testUsername, testPassword)
+
+	redacted := vms.Redact("login " + testUsername + " with " + testPassword)
+	if strings.Contains(redacted, testUsername) || strings.Contains(redacted, testPassword) {
+		t.Fatalf("credentials not redacted: %s", redacted)
+	}
+}
+
+func TestContextCancelled(t *testing.T) {
+	fake := newFake(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := fake.Client().GetServersInfoContext(ctx); err == nil {
+		t.Fatal("expected an error with a cancelled context")
+	}
+
+	if len(fake.Requests()) != 0 {
+		t.Fatalf("request sent despite cancelled context")
+	}
+}
+
+func TestDIDInfoJSONRoundTrip(t *testing.T) {
+	cassette, err := voipmstest.LoadCassette("testdata/cassettes/dids.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := cassette.Body("getDIDsInfo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := v1.ParseGetDidsInfo(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := json.Marshal(response.DIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded []v1.DIDInfo
+	if err = json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(response.DIDs, decoded) {
+		t.Fatalf("round trip mismatch\n%+v\n%+v", response.DIDs, decoded)
+	}
+
+	if !bytes.Contains(encoded, []byte(`"reseller_next_billing":"0000-00-00"`)) {
+		t.Fatalf("zero date not preserved: %s", encoded)
+	}
+
+	if !bytes.Contains(encoded, []byte(`"order_date":"2021-03-04T10:11:12Z"`)) {
+		t.Fatalf("date time not preserved: %s", encoded)
+	}
+}
+
+func TestDateTimeLocation(t *testing.T) {
+	fake := newFake(t)
+	utc := fake.Client()
+	eastern := fake.Client()
+	eastern.Location = time.FixedZone("EST", -5*60*60)
+
+	did, err := utc.GetDidInfo("", "5145550100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !did.OrderDate.Equal(time.Date(2021, 3, 4, 10, 11, 12, 0, time.UTC)) {
+		t.Fatalf("unexpected UTC order date %v", did.OrderDate.Time)
+	}
+
+	did, err = eastern.GetDidInfo("", "5145550100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !did.OrderDate.Equal(time.Date(2021, 3, 4, 15, 11, 12, 0, time.UTC)) {
+		t.Fatalf("unexpected EST order date %v", did.OrderDate.UTC())
+	}
+
+	text, err := did.OrderDate.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(text) != "2021-03-04 10:11:12" {
+		t.Fatalf("unexpected text %s", text)
+	}
+
+	encoded, err := json.Marshal(did)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded v1.DIDInfo
+	if err = json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !decoded.OrderDate.Equal(did.OrderDate.Time) || !decoded.NextBilling.Equal(did.NextBilling.Time) {
+		t.Fatalf("EST dates moved in a JSON round trip: %v, %v", decoded.OrderDate.Time, decoded.NextBilling.Time)
+	}
+
+	if _, offset := decoded.OrderDate.Zone(); offset != -5*60*60 {
+		t.Fatalf("offset lost in a JSON round trip: %v", decoded.OrderDate.Time)
+	}
+}
+
+func TestDateTimeJSONReadsBothForms(t *testing.T) {
+	var dates struct {
+		Wire    v1.VoIpMsDateTime `json:"wire"`
+		RFC3339 v1.VoIpMsDateTime `json:"rfc3339"`
+		Day     v1.VoIpMsDate     `json:"day"`
+		Zero    v1.VoIpMsDate     `json:"zero"`
+	}
+
+	data := []byte(`{"wire":"2021-03-04 15:11:12","rfc3339":"2021-03-04T10:11:12-05:00","day":"2023-04-01T00:00:00-04:00","zero":"0000-00-00"}`)
+	if err := json.Unmarshal(data, &dates); err != nil {
+		t.Fatal(err)
+	}
+
+	if !dates.Wire.Equal(dates.RFC3339.Time) {
+		t.Fatalf("%v and %v should be the same instant", dates.Wire.Time, dates.RFC3339.Time)
+	}
+
+	if !dates.Day.Equal(time.Date(2023, 4, 1, 4, 0, 0, 0, time.UTC)) || !dates.Zero.IsZero() {
+		t.Fatalf("unexpected dates %v, %v", dates.Day.Time, dates.Zero.Time)
+	}
+}
+
+func TestScalarTextRoundTrip(t *testing.T) {
+	values := []encoding.TextMarshaler{
+		v1.VoIpMsStringBool(true),
+		v1.VoIpMsStringBool(false),
+		v1.VoIpMsStringInt(-42),
+		v1.VoIpMsStringFloat(0.0095),
+		v1.VoIpMsDate{},
+		v1.VoIpMsDateTime{},
+	}
+
+	for _, value := range values {
+		text, err := value.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded := reflect.New(reflect.TypeOf(value))
+		if err = decoded.Interface().(encoding.TextUnmarshaler).UnmarshalText(text); err != nil {
+			t.Fatalf("%T %s: %v", value, text, err)
+		}
+
+		if !reflect.DeepEqual(decoded.Elem().Interface(), value) {
+			t.Fatalf("%T round trip mismatch: %v != %v", value, decoded.Elem().Interface(), value)
+		}
+	}
+}
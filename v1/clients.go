@@ -0,0 +1,241 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	url2 "net/url"
+	"reflect"
+	"strings"
+)
+
+// ClientFields holds the reseller client profile shared by addClient,
+// signupClient and setClient.
+type ClientFields struct {
+	FirstName         string `url:"firstname"`
+	LastName          string `url:"lastname"`
+	Company           string `url:"company,omitempty"`
+	Address           string `url:"address"`
+	City              string `url:"city"`
+	State             string `url:"state"`
+	Country           string `url:"country"`
+	Zip               string `url:"zip"`
+	PhoneNumber       string `url:"phone_number"`
+	Email             string `url:"email"`
+	Password          string `url:"password"`
+	BalanceManagement *int   `url:"balance_management"`
+}
+
+func ClientFieldsFromInfo(client *ClientInfo) ClientFields {
+	var balanceManagement *int
+
+	if client.BalanceManagement != 0 {
+		value := int(client.BalanceManagement)
+		balanceManagement = &value
+	}
+
+	return ClientFields{
+		FirstName:         client.FirstName,
+		LastName:          client.LastName,
+		Company:           client.Company,
+		Address:           client.Address,
+		City:              client.City,
+		State:             client.State,
+		Country:           client.Country,
+		Zip:               client.Zip,
+		PhoneNumber:       client.PhoneNumber,
+		Email:             client.Email,
+		Password:          client.Password,
+		BalanceManagement: balanceManagement,
+	}
+}
+
+func (f *ClientFields) Validate() error {
+	err := requireFields(
+		"firstname", f.FirstName,
+		"lastname", f.LastName,
+		"address", f.Address,
+		"city", f.City,
+		"state", f.State,
+		"country", f.Country,
+		"zip", f.Zip,
+		"phone_number", f.PhoneNumber,
+		"email", f.Email,
+		"password", f.Password,
+	)
+	if err != nil {
+		return err
+	}
+
+	if at := strings.Index(f.Email, "@"); at < 1 || at == len(f.Email)-1 {
+		return &ValidationError{Field: "email", Reason: "is not an email address"}
+	}
+
+	if strings.Trim(f.PhoneNumber, "0123456789") != "" {
+		return &ValidationError{Field: "phone_number", Reason: "must only contain digits"}
+	}
+
+	if f.BalanceManagement != nil && (*f.BalanceManagement < 1 || *f.BalanceManagement > 2) {
+		return &ValidationError{Field: "balance_management", Reason: "must be 1 or 2"}
+	}
+
+	return nil
+}
+
+type AddClientRequest struct {
+	BaseRequest
+	ClientFields
+}
+
+func (r *AddClientRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type SignupClientRequest struct {
+	BaseRequest
+	ClientFields
+	ConfirmEmail    string `url:"confirm_email"`
+	ConfirmPassword string `url:"confirm_password"`
+	Activate        bool   `url:"activate"`
+}
+
+func (r *SignupClientRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+func (r *SignupClientRequest) Validate() error {
+	if err := r.ClientFields.Validate(); err != nil {
+		return err
+	}
+
+	if r.ConfirmEmail != r.Email {
+		return &ValidationError{Field: "confirm_email", Reason: "does not match email"}
+	}
+
+	if r.ConfirmPassword != r.Password {
+		return &ValidationError{Field: "confirm_password", Reason: "does not match password"}
+	}
+
+	return nil
+}
+
+type SetClientRequest struct {
+	BaseRequest
+	Client string `url:"client"`
+	ClientFields
+}
+
+func (r *SetClientRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+func (r *SetClientRequest) Validate() error {
+	if err := requireFields("client", r.Client); err != nil {
+		return err
+	}
+	return r.ClientFields.Validate()
+}
+
+type AddClientResponse struct {
+	BaseResponse
+	Client VoIpMsStringInt `json:"client"`
+}
+
+func ParseAddClient(data *[]byte) (*AddClientResponse, error) {
+	response := &AddClientResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (vms *VoIpMsApi) AddClient(client ClientFields) (*AddClientResponse, error) {
+	return vms.AddClientContext(context.Background(), client)
+}
+
+func (vms *VoIpMsApi) AddClientContext(ctx context.Context, client ClientFields) (*AddClientResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if err = client.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "addClient", &AddClientRequest{
+		ClientFields: client,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseAddClient(data)
+}
+
+// SignupClient registers a client the same way the reseller signup page
+// does. The confirmation fields default to the email and password given.
+func (vms *VoIpMsApi) SignupClient(client ClientFields, activate bool) (*AddClientResponse, error) {
+	return vms.SignupClientContext(context.Background(), client, activate)
+}
+
+func (vms *VoIpMsApi) SignupClientContext(ctx context.Context, client ClientFields, activate bool) (*AddClientResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	request := &SignupClientRequest{
+		ClientFields:    client,
+		ConfirmEmail:    client.Email,
+		ConfirmPassword: client.Password,
+		Activate:        activate,
+	}
+
+	if err = request.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "signupClient", request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseAddClient(data)
+}
+
+func (vms *VoIpMsApi) SetClient(client string, fields ClientFields) (*BaseResponse, error) {
+	return vms.SetClientContext(context.Background(), client, fields)
+}
+
+func (vms *VoIpMsApi) SetClientContext(ctx context.Context, client string, fields ClientFields) (*BaseResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	request := &SetClientRequest{
+		Client:       client,
+		ClientFields: fields,
+	}
+
+	if err = request.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "setClient", request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBaseResponse(data)
+}
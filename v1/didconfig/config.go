@@ -0,0 +1,136 @@
+// Package didconfig keeps DID settings in a file and brings the account in
+// line with it.
+//
+//	dids:
+//	  "5145550100":
+//	    routing: account:100000_office
+//	    failover:
+//	      busy: vm:101
+//	      unreachable: "none:"
+//	      noanswer: vm:101
+//	    pop: montreal1.voip.ms
+//	    cnam: true
+//	    sms: true
+//	    note: front desk
+//
+// Settings left out of the file are not managed and stay as they are. A
+// routing ending with a colon, such as none:, must be quoted or YAML reads it
+// as a mapping.
+//
+//	config, _ := didconfig.LoadFile("dids.yaml")
+//	plan, _ := didconfig.NewPlan(ctx, vms, config)
+//	plan.Write(os.Stdout)
+//	err := plan.Apply(ctx, vms)
+package didconfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the desired state of the DIDs it lists, keyed by DID.
+type Config struct {
+	DIDs map[string]DIDState `yaml:"dids"`
+}
+
+// DIDState holds the settings managed for one DID; nil fields are not
+// managed. Pop is a server hostname such as montreal1.voip.ms or a POP
+// number. Description is only compared, the API offering no way to change
+// it.
+type DIDState struct {
+	Description *string           `yaml:"description"`
+	Routing     *v1.RoutingTarget `yaml:"routing"`
+	Failover    *FailoverState    `yaml:"failover"`
+	Pop         *string           `yaml:"pop"`
+	CNAM        *bool             `yaml:"cnam"`
+	SMS         *bool             `yaml:"sms"`
+	Note        *string           `yaml:"note"`
+}
+
+// FailoverState holds the failover routings, none: sends nothing.
+type FailoverState struct {
+	Busy        *v1.RoutingTarget `yaml:"busy"`
+	Unreachable *v1.RoutingTarget `yaml:"unreachable"`
+	NoAnswer    *v1.RoutingTarget `yaml:"noanswer"`
+}
+
+// Load reads a configuration, rejecting unknown keys and invalid routings.
+func Load(r io.Reader) (*Config, error) {
+	config := &Config{}
+
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func LoadFile(name string) (*Config, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	config, err := Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return config, nil
+}
+
+// Validate reports the first invalid setting, going through the DIDs in
+// order so the same file always gives the same error.
+func (c *Config) Validate() error {
+	dids := make([]string, 0, len(c.DIDs))
+	for did := range c.DIDs {
+		dids = append(dids, did)
+	}
+	sort.Strings(dids)
+
+	for _, did := range dids {
+		state := c.DIDs[did]
+		failover := state.Failover
+		if failover == nil {
+			failover = &FailoverState{}
+		}
+
+		routings := []struct {
+			field   string
+			routing *v1.RoutingTarget
+		}{
+			{"routing", state.Routing},
+			{"failover.busy", failover.Busy},
+			{"failover.unreachable", failover.Unreachable},
+			{"failover.noanswer", failover.NoAnswer},
+		}
+
+		for _, routing := range routings {
+			if routing.routing == nil {
+				continue
+			}
+			if err := routing.routing.Validate(); err != nil {
+				return fmt.Errorf("did %s: %s: %w", did, routing.field, err)
+			}
+		}
+
+		if state.Pop != nil && *state.Pop == "" {
+			return fmt.Errorf("did %s: pop is empty", did)
+		}
+	}
+
+	return nil
+}
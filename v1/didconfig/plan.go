@@ -0,0 +1,367 @@
+package didconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+// Field names used in a Change.
+const (
+	FieldDescription         = "description"
+	FieldRouting             = "routing"
+	FieldFailoverBusy        = "failover_busy"
+	FieldFailoverUnreachable = "failover_unreachable"
+	FieldFailoverNoAnswer    = "failover_noanswer"
+	FieldPop                 = "pop"
+	FieldCNAM                = "cnam"
+	FieldSMS                 = "sms"
+	FieldNote                = "note"
+)
+
+// Change is a setting of a DID that differs from the configuration. A
+// ReadOnly change, such as the description, has no API call to make it:
+// Apply leaves it as is, but it is still drift.
+type Change struct {
+	Field    string
+	From     string
+	To       string
+	ReadOnly bool
+}
+
+// DIDPlan lists the changes needed on one DID. Problems are settings of the
+// configuration the DID cannot take, such as SMS on a DID without SMS; Apply
+// makes the other changes and reports them as errors.
+type DIDPlan struct {
+	DID      string
+	Changes  []Change
+	Problems []string
+
+	current  v1.DIDInfo
+	settings v1.DIDSettings
+	sms      *bool
+}
+
+// Plan is the difference between a Config and the account. Missing lists
+// the DIDs of the configuration that are not on the account.
+type Plan struct {
+	DIDs    []DIDPlan
+	Missing []string
+}
+
+// NewPlan compares config with the account. The DIDs are read with a single
+// GetAllDidInfo call and the server list is fetched once when a POP is
+// given.
+func NewPlan(ctx context.Context, vms *v1.VoIpMsApi, config *Config) (*Plan, error) {
+	var (
+		err     error
+		dids    *v1.GetDidInfoResponse
+		servers *v1.GetServersInfoResponse
+	)
+
+	dids, err = vms.GetAllDidInfoContext(ctx)
+	if errors.Is(err, v1.ErrNoDID) {
+		dids = &v1.GetDidInfoResponse{}
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, state := range config.DIDs {
+		if state.Pop != nil {
+			if servers, err = vms.GetServersInfoContext(ctx); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	current := make(map[string]*v1.DIDInfo, len(dids.DIDs))
+	for i := range dids.DIDs {
+		current[dids.DIDs[i].DID] = &dids.DIDs[i]
+	}
+
+	names := make([]string, 0, len(config.DIDs))
+	for did := range config.DIDs {
+		names = append(names, did)
+	}
+	sort.Strings(names)
+
+	plan := &Plan{}
+	for _, did := range names {
+		info, ok := current[did]
+		if !ok {
+			plan.Missing = append(plan.Missing, did)
+			continue
+		}
+
+		didPlan, err := planDID(info, config.DIDs[did], servers)
+		if err != nil {
+			return nil, fmt.Errorf("did %s: %w", did, err)
+		}
+
+		if len(didPlan.Changes) > 0 || len(didPlan.Problems) > 0 {
+			plan.DIDs = append(plan.DIDs, *didPlan)
+		}
+	}
+
+	return plan, nil
+}
+
+// serverName renders pop with the hostname of its server when known.
+func serverName(servers *v1.GetServersInfoResponse, pop v1.VoIpMsStringInt) string {
+	for _, server := range servers.Servers {
+		if server.ServerPOP == pop {
+			return fmt.Sprintf("%s (%d)", server.ServerHostname, pop)
+		}
+	}
+	return strconv.FormatInt(int64(pop), 10)
+}
+
+// findPop accepts a server hostname or a POP number listed in servers.
+func findPop(servers *v1.GetServersInfoResponse, text string) (v1.VoIpMsStringInt, error) {
+	number, numberErr := strconv.ParseInt(text, 10, 64)
+
+	for _, server := range servers.Servers {
+		if server.ServerHostname == text || (numberErr == nil && int64(server.ServerPOP) == number) {
+			return server.ServerPOP, nil
+		}
+	}
+
+	return 0, fmt.Errorf("couldn't find POP for %s", text)
+}
+
+func planDID(info *v1.DIDInfo, state DIDState, servers *v1.GetServersInfoResponse) (*DIDPlan, error) {
+	didPlan := &DIDPlan{
+		DID:      info.DID,
+		current:  *info,
+		settings: v1.DIDSettingsFromInfo(info),
+	}
+
+	change := func(field string, from string, to string) {
+		if from != to {
+			didPlan.Changes = append(didPlan.Changes, Change{Field: field, From: from, To: to})
+		}
+	}
+
+	// setDIDInfo has no description parameter.
+	if state.Description != nil && info.Description != *state.Description {
+		didPlan.Changes = append(didPlan.Changes, Change{
+			Field:    FieldDescription,
+			From:     info.Description,
+			To:       *state.Description,
+			ReadOnly: true,
+		})
+	}
+
+	failover := state.Failover
+	if failover == nil {
+		failover = &FailoverState{}
+	}
+
+	routings := []struct {
+		field   string
+		desired *v1.RoutingTarget
+		target  *v1.RoutingTarget
+	}{
+		{FieldRouting, state.Routing, &didPlan.settings.Routing},
+		{FieldFailoverBusy, failover.Busy, &didPlan.settings.FailoverBusy},
+		{FieldFailoverUnreachable, failover.Unreachable, &didPlan.settings.FailoverUnreachable},
+		{FieldFailoverNoAnswer, failover.NoAnswer, &didPlan.settings.FailoverNoAnswer},
+	}
+
+	for _, routing := range routings {
+		if routing.desired != nil {
+			change(routing.field, routing.target.String(), routing.desired.String())
+			*routing.target = *routing.desired
+		}
+	}
+
+	if state.Pop != nil {
+		pop, err := findPop(servers, *state.Pop)
+		if err != nil {
+			return nil, err
+		}
+		change(FieldPop, serverName(servers, info.Pop), serverName(servers, pop))
+		didPlan.settings.Pop = int(pop)
+	}
+
+	if state.CNAM != nil {
+		change(FieldCNAM, strconv.FormatBool(info.CNAM != 0), strconv.FormatBool(*state.CNAM))
+		didPlan.settings.CNAM = *state.CNAM
+	}
+
+	if state.Note != nil {
+		change(FieldNote, info.Note, *state.Note)
+		didPlan.settings.Note = *state.Note
+	}
+
+	if state.SMS != nil {
+		if *state.SMS && info.SMSAvailable == 0 {
+			didPlan.Problems = append(didPlan.Problems, "sms is not available on this DID")
+		} else {
+			change(FieldSMS, strconv.FormatBool(info.SMSEnabled != 0), strconv.FormatBool(*state.SMS))
+			didPlan.sms = state.SMS
+		}
+	}
+
+	return didPlan, nil
+}
+
+// changes counts the changes of d that can be applied.
+func (d *DIDPlan) changes() int {
+	count := 0
+	for _, change := range d.Changes {
+		if !change.ReadOnly {
+			count++
+		}
+	}
+	return count
+}
+
+// HasDrift tells whether the account differs from the configuration in any
+// way, including read-only settings, problems and missing DIDs Apply cannot
+// fix.
+func (p *Plan) HasDrift() bool {
+	return len(p.DIDs) > 0 || len(p.Missing) > 0
+}
+
+// HasChanges tells whether Apply has changes to make.
+func (p *Plan) HasChanges() bool {
+	for i := range p.DIDs {
+		if p.DIDs[i].changes() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Write prints the plan for humans.
+func (p *Plan) Write(w io.Writer) error {
+	var (
+		changes  int
+		dids     int
+		readOnly int
+		problems int
+	)
+
+	for i, did := range p.DIDs {
+		if _, err := fmt.Fprintf(w, "~ %s\n", did.DID); err != nil {
+			return err
+		}
+		for _, change := range did.Changes {
+			note := ""
+			if change.ReadOnly {
+				note = " (read-only, not applied)"
+				readOnly++
+			}
+			if _, err := fmt.Fprintf(w, "    %s: %q -> %q%s\n", change.Field, change.From, change.To, note); err != nil {
+				return err
+			}
+		}
+		for _, problem := range did.Problems {
+			if _, err := fmt.Fprintf(w, "    ! %s\n", problem); err != nil {
+				return err
+			}
+			problems++
+		}
+		if count := p.DIDs[i].changes(); count > 0 {
+			changes += count
+			dids++
+		}
+	}
+
+	for _, did := range p.Missing {
+		if _, err := fmt.Fprintf(w, "! %s is not on the account\n", did); err != nil {
+			return err
+		}
+	}
+
+	if !p.HasDrift() {
+		_, err := fmt.Fprintln(w, "No changes, the account matches the configuration.")
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "Plan: %d changes on %d DIDs, %d DIDs missing.\n", changes, dids, len(p.Missing)); err != nil {
+		return err
+	}
+
+	if readOnly > 0 {
+		if _, err := fmt.Fprintf(w, "%d read-only settings differ from the configuration, apply cannot change them.\n", readOnly); err != nil {
+			return err
+		}
+	}
+
+	if problems > 0 {
+		if _, err := fmt.Fprintf(w, "%d settings cannot be applied to their DID.\n", problems); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Apply makes the calls needed to carry out the plan, one setDIDInfo per DID
+// unless only its routing or POP changes, and one setSMS when SMS changes.
+// Every DID is attempted; the errors are joined in the result.
+func (p *Plan) Apply(ctx context.Context, vms *v1.VoIpMsApi) error {
+	var errs []error
+
+	for i := range p.DIDs {
+		if err := p.DIDs[i].apply(ctx, vms); err != nil {
+			errs = append(errs, fmt.Errorf("did %s: %w", p.DIDs[i].DID, err))
+		}
+	}
+
+	for _, did := range p.Missing {
+		errs = append(errs, fmt.Errorf("did %s: not on the account, order it first", did))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (d *DIDPlan) apply(ctx context.Context, vms *v1.VoIpMsApi) error {
+	var (
+		err      error
+		errs     []error
+		settings []string
+	)
+
+	for _, change := range d.Changes {
+		if !change.ReadOnly && change.Field != FieldSMS {
+			settings = append(settings, change.Field)
+		}
+	}
+
+	switch {
+	case len(settings) == 0:
+	case len(settings) == 1 && settings[0] == FieldRouting:
+		_, err = vms.SetDidRoutingContext(ctx, d.DID, d.settings.Routing)
+	case len(settings) == 1 && settings[0] == FieldPop:
+		_, err = vms.SetDidPopContext(ctx, d.DID, v1.VoIpMsStringInt(d.settings.Pop))
+	default:
+		_, err = vms.SetDidInfoContext(ctx, d.DID, d.settings)
+	}
+
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if d.sms != nil && (d.current.SMSEnabled != 0) != *d.sms {
+		sms := v1.SMSSettingsFromInfo(&d.current)
+		sms.Enabled = *d.sms
+		if _, err = vms.SetSMSContext(ctx, d.DID, sms); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, problem := range d.Problems {
+		errs = append(errs, errors.New(problem))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,267 @@
+package didconfig_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+	"github.com/ticpu/voipms-gorest/v1/didconfig"
+	"github.com/ticpu/voipms-gorest/v1/voipmstest"
+)
+
+const desired = `
+dids:
+  "5145550100":
+    routing: grp:42
+    failover:
+      busy: vm:101
+    pop: toronto1.voip.ms
+    cnam: false
+    sms: true
+    note: front desk
+  "5145550101":
+    routing: vm:101
+    note: second line
+  "5145559999":
+    note: not ordered yet
+`
+
+func newFake(t *testing.T) *voipmstest.Server {
+	fake := voipmstest.NewServer("user@example.com", "secret")
+	t.Cleanup(fake.Close)
+
+	fake.AddServer(v1.ServerInfo{ServerHostname: "montreal1.voip.ms", ServerPOP: 8})
+	fake.AddServer(v1.ServerInfo{ServerHostname: "toronto1.voip.ms", ServerPOP: 29})
+	fake.AddDID(v1.DIDInfo{
+		DID:          "5145550100",
+		Routing:      v1.MustParseRoutingTarget("account:100000_office"),
+		Pop:          8,
+		Dialtime:     60,
+		BillingType:  1,
+		Note:         "front desk",
+		SMSAvailable: 1,
+	})
+	fake.AddDID(v1.DIDInfo{
+		DID:         "5145550101",
+		Routing:     v1.MustParseRoutingTarget("vm:101"),
+		Pop:         8,
+		Dialtime:    60,
+		BillingType: 1,
+	})
+
+	return fake
+}
+
+func TestLoad(t *testing.T) {
+	config, err := didconfig.Load(strings.NewReader(desired))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := config.DIDs["5145550100"]
+	if state.Routing == nil || state.Routing.Kind != v1.RoutingRingGroup || *state.Pop != "toronto1.voip.ms" || state.Failover.NoAnswer != nil {
+		t.Fatalf("unexpected state %+v", state)
+	}
+
+	for _, invalid := range []string{
+		"dids:\n  \"5145550100\":\n    routing: grp:sales\n",
+		"dids:\n  \"5145550100\":\n    rouitng: grp:42\n",
+	} {
+		if _, err = didconfig.Load(strings.NewReader(invalid)); err == nil {
+			t.Errorf("%q should not load", invalid)
+		}
+	}
+}
+
+func TestValidateOrder(t *testing.T) {
+	const invalid = `
+dids:
+  "5145550102":
+    routing: grp:x
+  "5145550101":
+    routing: vm:x
+    failover:
+      busy: fwd:x
+      noanswer: ivr:x
+`
+
+	for i := 0; i < 20; i++ {
+		_, err := didconfig.Load(strings.NewReader(invalid))
+		if err == nil || !strings.HasPrefix(err.Error(), "did 5145550101: routing:") {
+			t.Fatalf("expected the routing of the first DID to be reported, got %v", err)
+		}
+	}
+}
+
+func TestLoadQuotedNone(t *testing.T) {
+	config, err := didconfig.Load(strings.NewReader("dids:\n  \"5145550100\":\n    failover:\n      busy: \"none:\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if busy := config.DIDs["5145550100"].Failover.Busy; busy == nil || busy.Kind != v1.RoutingNone {
+		t.Fatalf("unexpected busy routing %+v", busy)
+	}
+
+	if _, err = didconfig.Load(strings.NewReader("dids:\n  \"5145550100\":\n    failover:\n      busy: none:\n")); err == nil {
+		t.Fatal("expected a YAML error for an unquoted none:")
+	}
+}
+
+func TestPlanAndApply(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	config, err := didconfig.Load(strings.NewReader(desired))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := didconfig.NewPlan(context.Background(), vms, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !plan.HasDrift() || !plan.HasChanges() || len(plan.DIDs) != 2 || len(plan.Missing) != 1 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+
+	var fields []string
+	for _, change := range plan.DIDs[0].Changes {
+		fields = append(fields, change.Field)
+	}
+	if strings.Join(fields, ",") != "routing,failover_busy,pop,sms" {
+		t.Fatalf("unexpected changes %+v", plan.DIDs[0].Changes)
+	}
+
+	var output strings.Builder
+	if err = plan.Write(&output); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), `pop: "montreal1.voip.ms (8)" -> "toronto1.voip.ms (29)"`) {
+		t.Fatalf("unexpected plan output:\n%s", output.String())
+	}
+
+	before := len(fake.Requests())
+	err = plan.Apply(context.Background(), vms)
+	if err == nil || !strings.Contains(err.Error(), "5145559999") {
+		t.Fatalf("expected the missing DID to be reported, got %v", err)
+	}
+
+	var methods []string
+	for _, params := range fake.Requests()[before:] {
+		methods = append(methods, params.Get("method"))
+	}
+	if strings.Join(methods, ",") != "setDIDInfo,setSMS,setDIDInfo" {
+		t.Fatalf("unexpected calls %v", methods)
+	}
+
+	did, _ := fake.DID("5145550100")
+	if did.Routing.String() != "grp:42" || did.Pop != 29 || did.SMSEnabled != 1 || did.Note != "front desk" || did.Dialtime != 60 {
+		t.Fatalf("unexpected did %+v", did)
+	}
+
+	delete(config.DIDs, "5145559999")
+	if plan, err = didconfig.NewPlan(context.Background(), vms, config); err != nil || plan.HasDrift() {
+		t.Fatalf("expected no drift after apply, got %+v, %v", plan, err)
+	}
+}
+
+func TestApplySingleSetting(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	config, err := didconfig.Load(strings.NewReader("dids:\n  \"5145550101\":\n    routing: grp:42\n    description: Laval\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := didconfig.NewPlan(context.Background(), vms, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := len(fake.Requests())
+	if err = plan.Apply(context.Background(), vms); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := fake.Requests()[before:]
+	if len(requests) != 1 || requests[0].Get("method") != "setDIDRouting" {
+		t.Fatalf("expected a single setDIDRouting, got %v", requests)
+	}
+}
+
+func TestSMSNotAvailableIsAProblem(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	config, err := didconfig.Load(strings.NewReader("dids:\n  \"5145550100\":\n    note: lobby\n  \"5145550101\":\n    sms: true\n    note: second line\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := didconfig.NewPlan(context.Background(), vms, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.DIDs) != 2 || len(plan.DIDs[1].Problems) != 1 || len(plan.DIDs[1].Changes) != 1 || plan.DIDs[1].Changes[0].Field != didconfig.FieldNote {
+		t.Fatalf("expected the SMS problem next to the other changes, got %+v", plan)
+	}
+
+	var output strings.Builder
+	if err = plan.Write(&output); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), "! sms is not available on this DID") {
+		t.Fatalf("problem not shown:\n%s", output.String())
+	}
+
+	err = plan.Apply(context.Background(), vms)
+	if err == nil || !strings.Contains(err.Error(), "did 5145550101: sms is not available") {
+		t.Fatalf("expected the problem to be reported, got %v", err)
+	}
+
+	first, _ := fake.DID("5145550100")
+	second, _ := fake.DID("5145550101")
+	if first.Note != "lobby" || second.Note != "second line" || second.SMSEnabled != 0 {
+		t.Fatalf("the other changes were not applied: %+v, %+v", first, second)
+	}
+}
+
+func TestDescriptionIsReadOnly(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	config, err := didconfig.Load(strings.NewReader("dids:\n  \"5145550101\":\n    description: Laval\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := didconfig.NewPlan(context.Background(), vms, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !plan.HasDrift() || plan.HasChanges() || len(plan.DIDs) != 1 || !plan.DIDs[0].Changes[0].ReadOnly {
+		t.Fatalf("expected only a read-only change, counted as drift, got %+v", plan)
+	}
+
+	var output strings.Builder
+	if err = plan.Write(&output); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), "read-only") || strings.Contains(output.String(), "No changes") {
+		t.Fatalf("read-only change not shown:\n%s", output.String())
+	}
+
+	before := len(fake.Requests())
+	if err = plan.Apply(context.Background(), vms); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.Requests()) != before {
+		t.Fatalf("calls made for a read-only change: %v", fake.Requests()[before:])
+	}
+}
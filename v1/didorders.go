@@ -0,0 +1,562 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	url2 "net/url"
+	"reflect"
+)
+
+// DIDSearchType tells how a DID search query is matched against numbers.
+type DIDSearchType string
+
+const (
+	DIDSearchStarts   DIDSearchType = "starts"
+	DIDSearchContains DIDSearchType = "contains"
+	DIDSearchEnds     DIDSearchType = "ends"
+)
+
+// DIDSearch is the query shared by the DID and toll free searches, such as
+// {DIDSearchStarts, "514"}.
+type DIDSearch struct {
+	Type  DIDSearchType `url:"type"`
+	Query string        `url:"query"`
+}
+
+func (s *DIDSearch) Validate() error {
+	switch s.Type {
+	case DIDSearchStarts, DIDSearchContains, DIDSearchEnds:
+	default:
+		return &ValidationError{Field: "type", Reason: "must be starts, contains or ends"}
+	}
+
+	if s.Query == "" {
+		return &ValidationError{Field: "query", Reason: "is required"}
+	}
+
+	return nil
+}
+
+type Province struct {
+	Province    string `json:"province"`
+	Description string `json:"description"`
+}
+
+type State struct {
+	State       string `json:"state"`
+	Description string `json:"description"`
+}
+
+// RateCenter is a local calling area DIDs can be ordered in; Available is
+// "yes" when numbers are left.
+type RateCenter struct {
+	RateCenter string `json:"ratecenter"`
+	Available  string `json:"available"`
+}
+
+// AvailableDID is a number returned by searchDIDsCAN and searchDIDsUSA along
+// with its per minute and flat rate prices. Province is set for Canadian
+// numbers and State for American ones.
+type AvailableDID struct {
+	DID                 string          `json:"did"`
+	RateCenter          string          `json:"ratecenter"`
+	Province            string          `json:"province,omitempty"`
+	ProvinceDescription string          `json:"province_description,omitempty"`
+	State               string          `json:"state,omitempty"`
+	StateDescription    string          `json:"state_description,omitempty"`
+	PerMinuteMonthly    VoIpMsMoney     `json:"perminute_monthly"`
+	PerMinuteMinute     VoIpMsMoney     `json:"perminute_minute"`
+	PerMinuteSetup      VoIpMsMoney     `json:"perminute_setup"`
+	FlatMonthly         VoIpMsMoney     `json:"flat_monthly"`
+	FlatMinute          VoIpMsMoney     `json:"flat_minute"`
+	FlatSetup           VoIpMsMoney     `json:"flat_setup"`
+	SMS                 VoIpMsStringInt `json:"sms"`
+}
+
+// TollFreeDID is a number returned by searchTollFreeCanUS.
+type TollFreeDID struct {
+	DID     string      `json:"did"`
+	Monthly VoIpMsMoney `json:"monthly"`
+	Minute  VoIpMsMoney `json:"minute"`
+	Setup   VoIpMsMoney `json:"setup"`
+}
+
+type GetProvincesRequest struct {
+	BaseRequest
+}
+
+func (r *GetProvincesRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type GetStatesRequest struct {
+	BaseRequest
+}
+
+func (r *GetStatesRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type GetRateCentersCANRequest struct {
+	BaseRequest
+	Province string `url:"province"`
+}
+
+func (r *GetRateCentersCANRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type GetRateCentersUSARequest struct {
+	BaseRequest
+	State string `url:"state"`
+}
+
+func (r *GetRateCentersUSARequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type SearchDIDsCANRequest struct {
+	BaseRequest
+	Province string `url:"province,omitempty"`
+	DIDSearch
+}
+
+func (r *SearchDIDsCANRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type SearchDIDsUSARequest struct {
+	BaseRequest
+	State string `url:"state,omitempty"`
+	DIDSearch
+}
+
+func (r *SearchDIDsUSARequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type SearchTollFreeRequest struct {
+	BaseRequest
+	DIDSearch
+}
+
+func (r *SearchTollFreeRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+// DIDOrder holds the settings a new DID starts with. Account, Monthly, Setup
+// and Minute bill the DID to a reseller client. With Test set VoIP.ms checks
+// the order without placing it.
+type DIDOrder struct {
+	DIDSettings
+	Account string      `url:"account,omitempty"`
+	Monthly VoIpMsMoney `url:"monthly,omitempty"`
+	Setup   VoIpMsMoney `url:"setup,omitempty"`
+	Minute  VoIpMsMoney `url:"minute,omitempty"`
+	Test    bool        `url:"test,omitempty"`
+}
+
+type OrderDIDRequest struct {
+	BaseRequest
+	Did string `url:"did"`
+	DIDOrder
+}
+
+func (r *OrderDIDRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type OrderDIDVirtualRequest struct {
+	BaseRequest
+	Digits string `url:"digits"`
+	DIDOrder
+}
+
+func (r *OrderDIDVirtualRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type CancelDIDRequest struct {
+	BaseRequest
+	Did           string `url:"did"`
+	CancelComment string `url:"cancelcomment,omitempty"`
+	PortOut       bool   `url:"portout,omitempty"`
+	Test          bool   `url:"test,omitempty"`
+}
+
+func (r *CancelDIDRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type GetProvincesResponse struct {
+	BaseResponse
+	Provinces []Province `json:"provinces"`
+}
+
+type GetStatesResponse struct {
+	BaseResponse
+	States []State `json:"states"`
+}
+
+type GetRateCentersResponse struct {
+	BaseResponse
+	RateCenters []RateCenter `json:"ratecenters"`
+}
+
+type SearchDIDsResponse struct {
+	BaseResponse
+	DIDs []AvailableDID `json:"dids"`
+}
+
+type SearchTollFreeResponse struct {
+	BaseResponse
+	DIDs []TollFreeDID `json:"dids"`
+}
+
+// OrderDIDResponse carries the number assigned by orderDIDVirtual; DID is
+// empty for the other orders.
+type OrderDIDResponse struct {
+	BaseResponse
+	DID string `json:"did"`
+}
+
+func ParseGetProvinces(data *[]byte) (*GetProvincesResponse, error) {
+	response := &GetProvincesResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func ParseGetStates(data *[]byte) (*GetStatesResponse, error) {
+	response := &GetStatesResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func ParseGetRateCenters(data *[]byte) (*GetRateCentersResponse, error) {
+	response := &GetRateCentersResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func ParseSearchDIDs(data *[]byte) (*SearchDIDsResponse, error) {
+	response := &SearchDIDsResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func ParseSearchTollFree(data *[]byte) (*SearchTollFreeResponse, error) {
+	response := &SearchTollFreeResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func ParseOrderDID(data *[]byte) (*OrderDIDResponse, error) {
+	response := &OrderDIDResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (vms *VoIpMsApi) GetProvinces() (*GetProvincesResponse, error) {
+	return vms.GetProvincesContext(context.Background())
+}
+
+func (vms *VoIpMsApi) GetProvincesContext(ctx context.Context) (*GetProvincesResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getProvinces", &GetProvincesRequest{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseGetProvinces(data)
+}
+
+func (vms *VoIpMsApi) GetStates() (*GetStatesResponse, error) {
+	return vms.GetStatesContext(context.Background())
+}
+
+func (vms *VoIpMsApi) GetStatesContext(ctx context.Context) (*GetStatesResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getStates", &GetStatesRequest{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseGetStates(data)
+}
+
+// GetRateCentersCAN lists the rate centers of a Canadian province, such as QC.
+func (vms *VoIpMsApi) GetRateCentersCAN(province string) (*GetRateCentersResponse, error) {
+	return vms.GetRateCentersCANContext(context.Background(), province)
+}
+
+func (vms *VoIpMsApi) GetRateCentersCANContext(ctx context.Context, province string) (*GetRateCentersResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if err = requireFields("province", province); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getRateCentersCAN", &GetRateCentersCANRequest{
+		Province: province,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseGetRateCenters(data)
+}
+
+// GetRateCentersUSA lists the rate centers of an American state, such as NY.
+func (vms *VoIpMsApi) GetRateCentersUSA(state string) (*GetRateCentersResponse, error) {
+	return vms.GetRateCentersUSAContext(context.Background(), state)
+}
+
+func (vms *VoIpMsApi) GetRateCentersUSAContext(ctx context.Context, state string) (*GetRateCentersResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if err = requireFields("state", state); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getRateCentersUSA", &GetRateCentersUSARequest{
+		State: state,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseGetRateCenters(data)
+}
+
+// SearchDIDsCAN lists the Canadian numbers available for ordering, in
+// province when it is not empty. No match gives an empty list.
+func (vms *VoIpMsApi) SearchDIDsCAN(province string, search DIDSearch) (*SearchDIDsResponse, error) {
+	return vms.SearchDIDsCANContext(context.Background(), province, search)
+}
+
+func (vms *VoIpMsApi) SearchDIDsCANContext(ctx context.Context, province string, search DIDSearch) (*SearchDIDsResponse, error) {
+	if err := search.Validate(); err != nil {
+		return nil, err
+	}
+
+	return vms.searchDIDs(ctx, "searchDIDsCAN", &SearchDIDsCANRequest{
+		Province:  province,
+		DIDSearch: search,
+	})
+}
+
+// SearchDIDsUSA lists the American numbers available for ordering, in state
+// when it is not empty. No match gives an empty list.
+func (vms *VoIpMsApi) SearchDIDsUSA(state string, search DIDSearch) (*SearchDIDsResponse, error) {
+	return vms.SearchDIDsUSAContext(context.Background(), state, search)
+}
+
+func (vms *VoIpMsApi) SearchDIDsUSAContext(ctx context.Context, state string, search DIDSearch) (*SearchDIDsResponse, error) {
+	if err := search.Validate(); err != nil {
+		return nil, err
+	}
+
+	return vms.searchDIDs(ctx, "searchDIDsUSA", &SearchDIDsUSARequest{
+		State:     state,
+		DIDSearch: search,
+	})
+}
+
+func (vms *VoIpMsApi) searchDIDs(ctx context.Context, method string, request RequestParams) (*SearchDIDsResponse, error) {
+	data, err := vms.NewHttpRequestContext(ctx, http.MethodGet, method, request)
+	if errors.Is(err, ErrNoDID) {
+		return &SearchDIDsResponse{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return ParseSearchDIDs(data)
+}
+
+// SearchTollFree lists the Canadian and American toll free numbers available
+// for ordering. No match gives an empty list.
+func (vms *VoIpMsApi) SearchTollFree(search DIDSearch) (*SearchTollFreeResponse, error) {
+	return vms.SearchTollFreeContext(context.Background(), search)
+}
+
+func (vms *VoIpMsApi) SearchTollFreeContext(ctx context.Context, search DIDSearch) (*SearchTollFreeResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if err = search.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "searchTollFreeCanUS", &SearchTollFreeRequest{
+		DIDSearch: search,
+	})
+	if errors.Is(err, ErrNoDID) {
+		return &SearchTollFreeResponse{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return ParseSearchTollFree(data)
+}
+
+// OrderDID buys did, a number found with SearchDIDsCAN or SearchDIDsUSA.
+// This charges the account unless order.Test is set.
+func (vms *VoIpMsApi) OrderDID(did string, order DIDOrder) (*OrderDIDResponse, error) {
+	return vms.OrderDIDContext(context.Background(), did, order)
+}
+
+func (vms *VoIpMsApi) OrderDIDContext(ctx context.Context, did string, order DIDOrder) (*OrderDIDResponse, error) {
+	return vms.orderDID(ctx, "orderDID", did, order)
+}
+
+// OrderTollFree buys did, a number found with SearchTollFree. This charges
+// the account unless order.Test is set.
+func (vms *VoIpMsApi) OrderTollFree(did string, order DIDOrder) (*OrderDIDResponse, error) {
+	return vms.OrderTollFreeContext(context.Background(), did, order)
+}
+
+func (vms *VoIpMsApi) OrderTollFreeContext(ctx context.Context, did string, order DIDOrder) (*OrderDIDResponse, error) {
+	return vms.orderDID(ctx, "orderTollFree", did, order)
+}
+
+func (vms *VoIpMsApi) orderDID(ctx context.Context, method string, did string, order DIDOrder) (*OrderDIDResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if err = requireFields("did", did); err != nil {
+		return nil, err
+	}
+
+	if err = order.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, method, &OrderDIDRequest{
+		Did:      did,
+		DIDOrder: order,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseOrderDID(data)
+}
+
+// OrderDIDVirtual creates a virtual number ending with digits, reachable
+// only from within VoIP.ms. The number assigned is returned in the response.
+func (vms *VoIpMsApi) OrderDIDVirtual(digits string, order DIDOrder) (*OrderDIDResponse, error) {
+	return vms.OrderDIDVirtualContext(context.Background(), digits, order)
+}
+
+func (vms *VoIpMsApi) OrderDIDVirtualContext(ctx context.Context, digits string, order DIDOrder) (*OrderDIDResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if err = requireFields("digits", digits); err != nil {
+		return nil, err
+	}
+
+	if err = order.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "orderDIDVirtual", &OrderDIDVirtualRequest{
+		Digits:   digits,
+		DIDOrder: order,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseOrderDID(data)
+}
+
+// CancelDID releases did. With portOut the number is kept available for a
+// port to another carrier; with test VoIP.ms only checks the request.
+func (vms *VoIpMsApi) CancelDID(did string, comment string, portOut bool, test bool) (*BaseResponse, error) {
+	return vms.CancelDIDContext(context.Background(), did, comment, portOut, test)
+}
+
+func (vms *VoIpMsApi) CancelDIDContext(ctx context.Context, did string, comment string, portOut bool, test bool) (*BaseResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if err = requireFields("did", did); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "cancelDID", &CancelDIDRequest{
+		Did:           did,
+		CancelComment: comment,
+		PortOut:       portOut,
+		Test:          test,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBaseResponse(data)
+}
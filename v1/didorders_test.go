@@ -0,0 +1,162 @@
+package v1_test
+
+import (
+	"errors"
+	"testing"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+	"github.com/ticpu/voipms-gorest/v1/voipmstest"
+)
+
+func addInventory(fake *voipmstest.Server) {
+	fake.AddProvince(v1.Province{Province: "QC", Description: "Quebec"})
+	fake.AddState(v1.State{State: "NY", Description: "New York"})
+	fake.AddAvailableDID(v1.AvailableDID{
+		DID:              "5145550111",
+		RateCenter:       "MONTREAL",
+		Province:         "QC",
+		PerMinuteMonthly: v1.MustParseMoney("0.85"),
+		FlatMonthly:      v1.MustParseMoney("4.95"),
+		SMS:              1,
+	})
+	fake.AddAvailableDID(v1.AvailableDID{DID: "4505550112", RateCenter: "LAVAL", Province: "QC"})
+	fake.AddAvailableDID(v1.AvailableDID{DID: "2125550113", RateCenter: "NEW YORK", State: "NY"})
+	fake.AddTollFreeDID(v1.TollFreeDID{DID: "8445550114", Monthly: v1.MustParseMoney("1.49")})
+}
+
+func newOrder() v1.DIDOrder {
+	return v1.DIDOrder{DIDSettings: v1.DIDSettings{
+		Routing:     v1.MustParseRoutingTarget("account:100000_office"),
+		Pop:         8,
+		Dialtime:    60,
+		BillingType: 1,
+		Note:        "new customer",
+	}}
+}
+
+func TestSearchDIDs(t *testing.T) {
+	fake := newFake(t)
+	addInventory(fake)
+	vms := fake.Client()
+
+	dids, err := vms.SearchDIDsCAN("QC", v1.DIDSearch{Type: v1.DIDSearchStarts, Query: "514"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dids.DIDs) != 1 || dids.DIDs[0].DID != "5145550111" || dids.DIDs[0].FlatMonthly != v1.MustParseMoney("4.95") {
+		t.Fatalf("unexpected dids %+v", dids.DIDs)
+	}
+
+	if dids, err = vms.SearchDIDsUSA("", v1.DIDSearch{Type: v1.DIDSearchContains, Query: "555"}); err != nil || len(dids.DIDs) != 1 {
+		t.Fatalf("unexpected dids %+v, %v", dids, err)
+	}
+
+	if dids, err = vms.SearchDIDsCAN("", v1.DIDSearch{Type: v1.DIDSearchEnds, Query: "9999"}); err != nil || len(dids.DIDs) != 0 {
+		t.Fatalf("expected no match, got %+v, %v", dids, err)
+	}
+
+	tollFree, err := vms.SearchTollFree(v1.DIDSearch{Type: v1.DIDSearchStarts, Query: "844"})
+	if err != nil || len(tollFree.DIDs) != 1 {
+		t.Fatalf("unexpected toll free dids %+v, %v", tollFree, err)
+	}
+
+	var validationError *v1.ValidationError
+	if _, err = vms.SearchDIDsCAN("QC", v1.DIDSearch{Type: "near", Query: "514"}); !errors.As(err, &validationError) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+}
+
+func TestRateCenters(t *testing.T) {
+	fake := newFake(t)
+	addInventory(fake)
+	vms := fake.Client()
+
+	provinces, err := vms.GetProvinces()
+	if err != nil || len(provinces.Provinces) != 1 || provinces.Provinces[0].Description != "Quebec" {
+		t.Fatalf("unexpected provinces %+v, %v", provinces, err)
+	}
+
+	rateCenters, err := vms.GetRateCentersCAN("QC")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rateCenters.RateCenters) != 2 || rateCenters.RateCenters[0].RateCenter != "MONTREAL" {
+		t.Fatalf("unexpected rate centers %+v", rateCenters.RateCenters)
+	}
+
+	if _, err = vms.GetRateCentersUSA("ZZ"); !errors.Is(err, v1.ErrInvalidState) {
+		t.Fatalf("expected ErrInvalidState, got %v", err)
+	}
+}
+
+func TestOrderDID(t *testing.T) {
+	fake := newFake(t)
+	addInventory(fake)
+	vms := fake.Client()
+
+	order := newOrder()
+	order.Test = true
+	if _, err := vms.OrderDID("5145550111", order); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := fake.DID("5145550111"); ok {
+		t.Fatal("a test order should not add the DID")
+	}
+
+	order.Test = false
+	if _, err := vms.OrderDID("5145550111", order); err != nil {
+		t.Fatal(err)
+	}
+
+	did, ok := fake.DID("5145550111")
+	if !ok || did.Note != "new customer" || did.Routing.String() != "account:100000_office" {
+		t.Fatalf("unexpected did %+v", did)
+	}
+
+	if _, err := vms.OrderDID("5145550111", order); !errors.Is(err, v1.ErrDIDUnavailable) {
+		t.Fatalf("expected ErrDIDUnavailable, got %v", err)
+	}
+
+	if _, err := vms.OrderTollFree("8445550114", order); err != nil {
+		t.Fatal(err)
+	}
+
+	virtual, err := vms.OrderDIDVirtual("123", order)
+	if err != nil || virtual.DID == "" {
+		t.Fatalf("unexpected virtual order %+v, %v", virtual, err)
+	}
+
+	var validationError *v1.ValidationError
+	if _, err = vms.OrderDID("2125550113", v1.DIDOrder{}); !errors.As(err, &validationError) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+}
+
+func TestCancelDID(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	if _, err := vms.CancelDID("5145550100", "moving", false, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := fake.DID("5145550100"); !ok {
+		t.Fatal("a test cancellation should keep the DID")
+	}
+
+	if _, err := vms.CancelDID("5145550100", "moving", false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := fake.DID("5145550100"); ok {
+		t.Fatal("the DID was not cancelled")
+	}
+
+	params := fake.Requests()[len(fake.Requests())-1]
+	if params.Get("cancelcomment") != "moving" || params.Has("portout") {
+		t.Fatalf("unexpected parameters %v", params)
+	}
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -26,13 +27,19 @@ type GetDidInfoRequest struct {
 	Did    string `url:"did,omitempty"`
 }
 
+func (r *GetDidInfoRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
 type DIDInfo struct {
 	DID                   string            `json:"did"`
 	Description           string            `json:"description"`
-	Routing               string            `json:"routing"`
-	FailoverBusy          string            `json:"failover_busy"`
-	FailoverUnreachable   string            `json:"failover_unreachable"`
-	FailoverNoAnswer      string            `json:"failover_noanswer"`
+	Routing               RoutingTarget     `json:"routing"`
+	FailoverBusy          RoutingTarget     `json:"failover_busy"`
+	FailoverUnreachable   RoutingTarget     `json:"failover_unreachable"`
+	FailoverNoAnswer      RoutingTarget     `json:"failover_noanswer"`
 	Voicemail             string            `json:"voicemail"`
 	Pop                   VoIpMsStringInt   `json:"pop"`
 	Dialtime              VoIpMsStringInt   `json:"dialtime"`
@@ -73,6 +80,9 @@ type GetDidInfoResponse struct {
 	DIDs []DIDInfo `json:"dids"`
 }
 
+// ParseGetDidsInfo decodes a getDIDsInfo response. Having no client, it
+// reads the dates as UTC; the GetDidInfo methods move them to the Location
+// of the client.
 func ParseGetDidsInfo(data *[]byte) (*GetDidInfoResponse, error) {
 	response := &GetDidInfoResponse{}
 	if err := json.Unmarshal(*data, response); err != nil {
@@ -82,27 +92,42 @@ func ParseGetDidsInfo(data *[]byte) (*GetDidInfoResponse, error) {
 }
 
 func (vms *VoIpMsApi) GetAllDidInfo() (*GetDidInfoResponse, error) {
+	return vms.GetAllDidInfoContext(context.Background())
+}
+
+func (vms *VoIpMsApi) GetAllDidInfoContext(ctx context.Context) (*GetDidInfoResponse, error) {
 	var (
-		err  error
-		data *[]byte
+		err      error
+		data     *[]byte
+		response *GetDidInfoResponse
 	)
 
-	data, err = vms.NewHttpRequest(http.MethodGet, "getDIDsInfo", &GetDidInfoRequest{})
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getDIDsInfo", &GetDidInfoRequest{})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return ParseGetDidsInfo(data)
+	if response, err = ParseGetDidsInfo(data); err != nil {
+		return nil, err
+	}
+
+	vms.localize(response)
+	return response, nil
 }
 
 func (vms *VoIpMsApi) GetAllClientDidInfo(client string) (*GetDidInfoResponse, error) {
+	return vms.GetAllClientDidInfoContext(context.Background(), client)
+}
+
+func (vms *VoIpMsApi) GetAllClientDidInfoContext(ctx context.Context, client string) (*GetDidInfoResponse, error) {
 	var (
-		err  error
-		data *[]byte
+		err      error
+		data     *[]byte
+		response *GetDidInfoResponse
 	)
 
-	data, err = vms.NewHttpRequest(http.MethodGet, "getDIDsInfo", &GetDidInfoRequest{
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getDIDsInfo", &GetDidInfoRequest{
 		Client: client,
 	})
 
@@ -110,17 +135,26 @@ func (vms *VoIpMsApi) GetAllClientDidInfo(client string) (*GetDidInfoResponse, e
 		return nil, err
 	}
 
-	return ParseGetDidsInfo(data)
+	if response, err = ParseGetDidsInfo(data); err != nil {
+		return nil, err
+	}
+
+	vms.localize(response)
+	return response, nil
 }
 
 func (vms *VoIpMsApi) GetDidInfo(client string, did string) (*DIDInfo, error) {
+	return vms.GetDidInfoContext(context.Background(), client, did)
+}
+
+func (vms *VoIpMsApi) GetDidInfoContext(ctx context.Context, client string, did string) (*DIDInfo, error) {
 	var (
 		err     error
 		data    *[]byte
 		didInfo *GetDidInfoResponse
 	)
 
-	data, err = vms.NewHttpRequest(http.MethodGet, "getDIDsInfo", &GetDidInfoRequest{
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getDIDsInfo", &GetDidInfoRequest{
 		Client: client,
 		Did:    did,
 	})
@@ -132,6 +166,7 @@ func (vms *VoIpMsApi) GetDidInfo(client string, did string) (*DIDInfo, error) {
 	if didInfo, err = ParseGetDidsInfo(data); err != nil {
 		return nil, err
 	}
+	vms.localize(didInfo)
 
 	for i := range didInfo.DIDs {
 		if didInfo.DIDs[i].DID == did {
@@ -143,12 +178,16 @@ func (vms *VoIpMsApi) GetDidInfo(client string, did string) (*DIDInfo, error) {
 }
 
 func (vms *VoIpMsApi) SetDidPop(did string, pop VoIpMsStringInt) (*BaseResponse, error) {
+	return vms.SetDidPopContext(context.Background(), did, pop)
+}
+
+func (vms *VoIpMsApi) SetDidPopContext(ctx context.Context, did string, pop VoIpMsStringInt) (*BaseResponse, error) {
 	var (
 		err  error
 		data *[]byte
 	)
 
-	data, err = vms.NewHttpRequest(http.MethodPatch, "setDIDPOP", &SetDidPopRequest{
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodPatch, "setDIDPOP", &SetDidPopRequest{
 		Did: did,
 		Pop: int(pop),
 	})
@@ -161,12 +200,16 @@ func (vms *VoIpMsApi) SetDidPop(did string, pop VoIpMsStringInt) (*BaseResponse,
 }
 
 func (vms *VoIpMsApi) SetDidPopByHostname(did string, popHostname string) (*BaseResponse, error) {
+	return vms.SetDidPopByHostnameContext(context.Background(), did, popHostname)
+}
+
+func (vms *VoIpMsApi) SetDidPopByHostnameContext(ctx context.Context, did string, popHostname string) (*BaseResponse, error) {
 	var (
 		err    error
 		server *ServerInfo
 	)
 
-	if server, err = vms.GetServersInfoForPopHostname(popHostname); err != nil {
+	if server, err = vms.GetServersInfoForPopHostnameContext(ctx, popHostname); err != nil {
 		return nil, err
 	}
 
@@ -174,5 +217,194 @@ func (vms *VoIpMsApi) SetDidPopByHostname(did string, popHostname string) (*Base
 		return nil, fmt.Errorf("couldn't find POP for %s", popHostname)
 	}
 
-	return vms.SetDidPop(did, server.ServerPOP)
+	return vms.SetDidPopContext(ctx, did, server.ServerPOP)
+}
+
+// DIDSettings holds the DID configuration sent to setDIDInfo. VoIP.ms
+// replaces every setting on each call, so start from DIDSettingsFromInfo to
+// change only some of them. The failovers and Voicemail are always sent: an
+// empty one clears the setting, as does none: for a failover.
+type DIDSettings struct {
+	Routing             RoutingTarget `url:"routing"`
+	FailoverBusy        RoutingTarget `url:"failover_busy"`
+	FailoverUnreachable RoutingTarget `url:"failover_unreachable"`
+	FailoverNoAnswer    RoutingTarget `url:"failover_noanswer"`
+	Voicemail           string        `url:"voicemail"`
+	Pop                 int           `url:"pop"`
+	Dialtime            int           `url:"dialtime"`
+	CNAM                bool          `url:"cnam"`
+	CallerIDPrefix      string        `url:"callerid_prefix"`
+	Note                string        `url:"note"`
+	BillingType         int           `url:"billing_type"`
+	RecordCalls         bool          `url:"record_calls"`
+	Transcribe          bool          `url:"transcribe"`
+	TranscriptionLocale string        `url:"transcription_locale,omitempty"`
+	TranscriptionEmail  string        `url:"transcription_email,omitempty"`
+}
+
+func DIDSettingsFromInfo(did *DIDInfo) DIDSettings {
+	return DIDSettings{
+		Routing:             did.Routing,
+		FailoverBusy:        did.FailoverBusy,
+		FailoverUnreachable: did.FailoverUnreachable,
+		FailoverNoAnswer:    did.FailoverNoAnswer,
+		Voicemail:           did.Voicemail,
+		Pop:                 int(did.Pop),
+		Dialtime:            int(did.Dialtime),
+		CNAM:                did.CNAM != 0,
+		CallerIDPrefix:      did.CallerIDPrefix,
+		Note:                did.Note,
+		BillingType:         int(did.BillingType),
+		RecordCalls:         did.RecordCalls != 0,
+		Transcribe:          did.Transcribe != 0,
+		TranscriptionLocale: did.TranscriptionLocale,
+		TranscriptionEmail:  did.TranscriptionEmail,
+	}
+}
+
+func (s *DIDSettings) Validate() error {
+	if err := s.Routing.validate("routing"); err != nil {
+		return err
+	}
+
+	failovers := []struct {
+		field  string
+		target RoutingTarget
+	}{
+		{"failover_busy", s.FailoverBusy},
+		{"failover_unreachable", s.FailoverUnreachable},
+		{"failover_noanswer", s.FailoverNoAnswer},
+	}
+
+	for _, failover := range failovers {
+		if failover.target.IsZero() {
+			continue
+		}
+		if err := failover.target.validate(failover.field); err != nil {
+			return err
+		}
+	}
+
+	if s.Pop <= 0 {
+		return &ValidationError{Field: "pop", Reason: "is required"}
+	}
+
+	if s.Dialtime <= 0 {
+		return &ValidationError{Field: "dialtime", Reason: "must be greater than zero"}
+	}
+
+	if s.BillingType < 1 || s.BillingType > 2 {
+		return &ValidationError{Field: "billing_type", Reason: "must be 1 for per minute or 2 for flat rate"}
+	}
+
+	return nil
+}
+
+type SetDidInfoRequest struct {
+	BaseRequest
+	Did string `url:"did"`
+	DIDSettings
+}
+
+func (r *SetDidInfoRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type SetDidRoutingRequest struct {
+	BaseRequest
+	Did     string        `url:"did"`
+	Routing RoutingTarget `url:"routing"`
+}
+
+func (r *SetDidRoutingRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+// SetDidInfo replaces the settings of did.
+func (vms *VoIpMsApi) SetDidInfo(did string, settings DIDSettings) (*BaseResponse, error) {
+	return vms.SetDidInfoContext(context.Background(), did, settings)
+}
+
+func (vms *VoIpMsApi) SetDidInfoContext(ctx context.Context, did string, settings DIDSettings) (*BaseResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if err = requireFields("did", did); err != nil {
+		return nil, err
+	}
+
+	if err = settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "setDIDInfo", &SetDidInfoRequest{
+		Did:         did,
+		DIDSettings: settings,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBaseResponse(data)
+}
+
+// UpdateDidInfo reads the current settings of did, lets update change them
+// and sends them back, so the settings update leaves alone are preserved.
+func (vms *VoIpMsApi) UpdateDidInfo(did string, update func(settings *DIDSettings)) (*BaseResponse, error) {
+	return vms.UpdateDidInfoContext(context.Background(), did, update)
+}
+
+func (vms *VoIpMsApi) UpdateDidInfoContext(ctx context.Context, did string, update func(settings *DIDSettings)) (*BaseResponse, error) {
+	var (
+		err      error
+		info     *DIDInfo
+		settings DIDSettings
+	)
+
+	if info, err = vms.GetDidInfoContext(ctx, "", did); err != nil {
+		return nil, err
+	}
+
+	settings = DIDSettingsFromInfo(info)
+	update(&settings)
+
+	return vms.SetDidInfoContext(ctx, did, settings)
+}
+
+// SetDidRouting changes only the main routing of did.
+func (vms *VoIpMsApi) SetDidRouting(did string, routing RoutingTarget) (*BaseResponse, error) {
+	return vms.SetDidRoutingContext(context.Background(), did, routing)
+}
+
+func (vms *VoIpMsApi) SetDidRoutingContext(ctx context.Context, did string, routing RoutingTarget) (*BaseResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if err = requireFields("did", did); err != nil {
+		return nil, err
+	}
+
+	if err = routing.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "setDIDRouting", &SetDidRoutingRequest{
+		Did:     did,
+		Routing: routing,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBaseResponse(data)
 }
@@ -0,0 +1,460 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func TestGetAllDidInfo(t *testing.T) {
+	fake := newFake(t)
+
+	response, err := fake.Client().GetAllDidInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Status != "success" || len(response.DIDs) != 2 {
+		t.Fatalf("unexpected response %+v", response)
+	}
+}
+
+func TestGetAllDidInfoUnexpectedRouting(t *testing.T) {
+	fake := newFake(t)
+	fake.AddDID(v1.DIDInfo{DID: "5145550102", Routing: v1.RoutingTarget{Value: "legacy"}})
+
+	response, err := fake.Client().GetAllDidInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.DIDs) != 3 || response.DIDs[2].Routing.String() != "legacy" {
+		t.Fatalf("unexpected response %+v", response.DIDs)
+	}
+}
+
+func TestGetDidInfo(t *testing.T) {
+	fake := newFake(t)
+
+	did, err := fake.Client().GetDidInfo("", "5145550100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if did.Description != "Main line" || did.Pop != 8 || did.Dialtime != 60 {
+		t.Fatalf("unexpected did %+v", did)
+	}
+
+	if !did.OrderDate.Equal(time.Date(2021, 3, 4, 10, 11, 12, 0, time.UTC)) {
+		t.Fatalf("unexpected order date %v", did.OrderDate)
+	}
+
+	if !did.NextBilling.Equal(time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected next billing %v", did.NextBilling)
+	}
+
+	if !did.ResellerNextBilling.IsZero() {
+		t.Fatalf("zero date not decoded as zero time: %v", did.ResellerNextBilling)
+	}
+}
+
+func TestGetAllClientDidInfo(t *testing.T) {
+	fake := newFake(t)
+
+	response, err := fake.Client().GetAllClientDidInfo("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.DIDs) != 1 || response.DIDs[0].DID != "4385550199" {
+		t.Fatalf("unexpected dids %+v", response.DIDs)
+	}
+
+	last := fake.Requests()[len(fake.Requests())-1]
+	if last.Get("client") != "12345" {
+		t.Fatalf("client parameter not sent: %v", last)
+	}
+
+	if _, ok := last["did"]; ok {
+		t.Fatalf("empty did parameter should be omitted: %v", last)
+	}
+}
+
+func TestGetDidInfoNotFound(t *testing.T) {
+	fake := newFake(t)
+
+	if _, err := fake.Client().GetDidInfo("", "5145559999"); err == nil {
+		t.Fatal("expected an error for an unknown DID")
+	}
+}
+
+func TestGetAllDidInfoEmpty(t *testing.T) {
+	fake := newFake(t)
+	fake.FailNext("getDIDsInfo", "no_did")
+
+	if _, err := fake.Client().GetAllDidInfo(); !errors.Is(err, v1.ErrNoDID) {
+		t.Fatalf("expected ErrNoDID, got %v", err)
+	}
+}
+
+func TestSetDidPop(t *testing.T) {
+	fake := newFake(t)
+
+	response, err := fake.Client().SetDidPop("5145550100", 29)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Status != "success" {
+		t.Fatalf("unexpected status %s", response.Status)
+	}
+
+	if did, _ := fake.DID("5145550100"); did.Pop != 29 {
+		t.Fatalf("pop not updated, got %d", did.Pop)
+	}
+}
+
+func TestSetDidPopInvalidDid(t *testing.T) {
+	fake := newFake(t)
+
+	_, err := fake.Client().SetDidPop("5145559999", 29)
+	if !errors.Is(err, v1.ErrInvalidDID) {
+		t.Fatalf("expected ErrInvalidDID, got %v", err)
+	}
+}
+
+func TestSetDidPopInvalidPop(t *testing.T) {
+	fake := newFake(t)
+
+	_, err := fake.Client().SetDidPop("5145550100", 99)
+	if !errors.Is(err, v1.ErrInvalidPOP) {
+		t.Fatalf("expected ErrInvalidPOP, got %v", err)
+	}
+}
+
+func TestSetDidPopByHostname(t *testing.T) {
+	fake := newFake(t)
+
+	if _, err := fake.Client().SetDidPopByHostname("5145550100", "toronto1.voip.ms"); err != nil {
+		t.Fatal(err)
+	}
+
+	if did, _ := fake.DID("5145550100"); did.Pop != 29 {
+		t.Fatalf("pop not updated, got %d", did.Pop)
+	}
+
+	if _, err := fake.Client().SetDidPopByHostname("5145550100", "nowhere.voip.ms"); err == nil {
+		t.Fatal("expected an error for an unknown hostname")
+	}
+}
+
+func TestParseRoutingTarget(t *testing.T) {
+	tests := []struct {
+		text  string
+		kind  v1.RoutingKind
+		value string
+	}{
+		{"account:100000_office", v1.RoutingAccount, "100000_office"},
+		{"fwd:1234", v1.RoutingForwarding, "1234"},
+		{"sip:sip:alice@example.com", v1.RoutingSIPURI, "sip:alice@example.com"},
+		{"none:", v1.RoutingNone, ""},
+		{"", "", ""},
+	}
+
+	for _, test := range tests {
+		target, err := v1.ParseRoutingTarget(test.text)
+		if err != nil {
+			t.Fatalf("%s: %v", test.text, err)
+		}
+		if target.Kind != test.kind || target.Value != test.value || target.String() != test.text {
+			t.Errorf("%s: unexpected %+v rendered as %q", test.text, target, target.String())
+		}
+	}
+
+	for _, invalid := range []string{"account", ":1234"} {
+		if _, err := v1.ParseRoutingTarget(invalid); err == nil {
+			t.Errorf("%q should not parse", invalid)
+		}
+	}
+}
+
+func TestSetDidRouting(t *testing.T) {
+	fake := newFake(t)
+
+	if _, err := fake.Client().SetDidRouting("5145550100", v1.MustParseRoutingTarget("grp:1234")); err != nil {
+		t.Fatal(err)
+	}
+
+	did, _ := fake.DID("5145550100")
+	if did.Routing.String() != "grp:1234" || did.Note != "front desk" {
+		t.Fatalf("unexpected did %+v", did)
+	}
+
+	var validationError *v1.ValidationError
+	if _, err := fake.Client().SetDidRouting("5145550100", v1.RoutingTarget{}); !errors.As(err, &validationError) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+}
+
+func TestUpdateDidInfoPreservesSettings(t *testing.T) {
+	fake := newFake(t)
+
+	_, err := fake.Client().UpdateDidInfo("5145550100", func(settings *v1.DIDSettings) {
+		settings.FailoverNoAnswer = v1.RoutingTarget{Kind: v1.RoutingVoicemail, Value: "101"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	did, _ := fake.DID("5145550100")
+	if did.FailoverNoAnswer.String() != "vm:101" || did.Routing.String() != "account:100000_office" {
+		t.Fatalf("unexpected routing %+v", did)
+	}
+
+	if did.Pop != 8 || did.Dialtime != 60 || did.Note != "front desk" || did.BillingType != 1 {
+		t.Fatalf("settings were not preserved: %+v", did)
+	}
+
+	params := fake.Requests()[len(fake.Requests())-1]
+	if params.Get("method") != "setDIDInfo" || params.Get("failover_noanswer") != "vm:101" || !params.Has("failover_busy") {
+		t.Fatalf("unexpected parameters %v", params)
+	}
+}
+
+func TestUpdateDidInfoClearsFailover(t *testing.T) {
+	fake := newFake(t)
+	fake.AddDID(v1.DIDInfo{
+		DID:          "5145550120",
+		Routing:      v1.MustParseRoutingTarget("account:100000_office"),
+		FailoverBusy: v1.MustParseRoutingTarget("vm:101"),
+		Voicemail:    "101",
+		Pop:          8,
+		Dialtime:     60,
+		BillingType:  1,
+	})
+
+	_, err := fake.Client().UpdateDidInfo("5145550120", func(settings *v1.DIDSettings) {
+		settings.FailoverBusy = v1.RoutingTarget{}
+		settings.Voicemail = ""
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := fake.Requests()[len(fake.Requests())-1]
+	if !params.Has("failover_busy") || params.Get("failover_busy") != "" || !params.Has("voicemail") {
+		t.Fatalf("cleared settings not sent: %v", params)
+	}
+
+	did, _ := fake.DID("5145550120")
+	if !did.FailoverBusy.IsZero() || did.Voicemail != "" {
+		t.Fatalf("failover not cleared: %+v", did)
+	}
+}
+
+func TestSetDidInfoValidation(t *testing.T) {
+	fake := newFake(t)
+
+	var validationError *v1.ValidationError
+	for _, settings := range []v1.DIDSettings{
+		{Pop: 8, Dialtime: 60, BillingType: 1},
+		{Routing: v1.MustParseRoutingTarget("vm:101"), Dialtime: 60, BillingType: 1},
+		{Routing: v1.MustParseRoutingTarget("vm:101"), Pop: 8, Dialtime: 60, BillingType: 3},
+	} {
+		if _, err := fake.Client().SetDidInfo("5145550100", settings); !errors.As(err, &validationError) {
+			t.Fatalf("expected ValidationError for %+v, got %v", settings, err)
+		}
+	}
+}
+
+func TestRoutingTargetValidate(t *testing.T) {
+	for _, valid := range []string{"account:100000", "account:100000_office", "fwd:1234", "vm:101", "grp:12", "sys:hangup", "none:"} {
+		if err := v1.MustParseRoutingTarget(valid).Validate(); err != nil {
+			t.Errorf("%s: %v", valid, err)
+		}
+	}
+
+	var validationError *v1.ValidationError
+	for _, invalid := range []string{"", "account:office", "fwd:", "grp:sales", "none:1", "bogus:1"} {
+		if err := v1.MustParseRoutingTarget(invalid).Validate(); !errors.As(err, &validationError) {
+			t.Errorf("%q: expected ValidationError, got %v", invalid, err)
+		}
+	}
+
+	settings := v1.DIDSettings{
+		Routing:      v1.MustParseRoutingTarget("vm:101"),
+		FailoverBusy: v1.MustParseRoutingTarget("ivr:main"),
+		Pop:          8,
+		Dialtime:     60,
+		BillingType:  1,
+	}
+	if err := settings.Validate(); !errors.As(err, &validationError) || validationError.Field != "failover_busy" {
+		t.Fatalf("expected a failover_busy ValidationError, got %v", err)
+	}
+}
+
+func TestDIDInfoRoutingJSON(t *testing.T) {
+	var info v1.DIDInfo
+	if err := json.Unmarshal([]byte(`{"routing":"grp:42","failover_busy":"none:","failover_noanswer":""}`), &info); err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Routing != (v1.RoutingTarget{Kind: v1.RoutingRingGroup, Value: "42"}) || info.FailoverBusy.Kind != v1.RoutingNone || !info.FailoverNoAnswer.IsZero() {
+		t.Fatalf("unexpected routing %+v", info)
+	}
+
+	encoded, err := json.Marshal(info.Routing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(encoded) != `"grp:42"` {
+		t.Fatalf("unexpected encoding %s", encoded)
+	}
+
+	var infos []v1.DIDInfo
+	if err := json.Unmarshal([]byte(`[{"did":"1","routing":"grp"},{"did":"2","routing":"grp:42"}]`), &infos); err != nil {
+		t.Fatalf("a routing without a type must not fail the list: %v", err)
+	}
+
+	if infos[0].Routing.String() != "grp" || infos[1].Routing.Kind != v1.RoutingRingGroup {
+		t.Fatalf("unexpected routings %+v", infos)
+	}
+
+	if err := infos[0].Routing.Validate(); err == nil {
+		t.Fatal("expected a validation error for a routing without a type")
+	}
+}
+
+func TestResolveRoutingConcurrent(t *testing.T) {
+	fake := newFake(t)
+	fake.AddRingGroup(v1.RingGroup{RingGroup: 42, Name: "Sales"})
+	fake.AddIVR(v1.IVR{IVR: 7, Name: "Main menu"})
+
+	var blocked sync.WaitGroup
+	block := make(chan struct{})
+	release := make(chan struct{})
+
+	vms := fake.Client()
+	vms.Use(func(next http.RoundTripper) http.RoundTripper {
+		return v1.RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			select {
+			case <-block:
+				blocked.Done()
+				<-release
+			default:
+			}
+			return next.RoundTrip(request)
+		})
+	})
+
+	resolver := v1.NewRoutingResolver(vms)
+	if _, err := resolver.Resolve(context.Background(), v1.MustParseRoutingTarget("ivr:7")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hold the ring group list while an IVR already cached is resolved.
+	blocked.Add(1)
+	close(block)
+	done := make(chan error, 1)
+	go func() {
+		_, err := resolver.Resolve(context.Background(), v1.MustParseRoutingTarget("grp:42"))
+		done <- err
+	}()
+	blocked.Wait()
+
+	resolved := make(chan error, 1)
+	go func() {
+		_, err := resolver.Resolve(context.Background(), v1.MustParseRoutingTarget("ivr:7"))
+		resolved <- err
+	}()
+
+	select {
+	case err := <-resolved:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cached IVRs blocked by the ring group list")
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveRouting(t *testing.T) {
+	fake := newFake(t)
+	fake.AddSubAccount(v1.SubAccount{Username: "office", Description: "Office"})
+	fake.AddForwarding(v1.Forwarding{Forwarding: 1234, PhoneNumber: "5145559999"})
+	fake.AddRingGroup(v1.RingGroup{RingGroup: 42, Name: "Sales"})
+	fake.AddIVR(v1.IVR{IVR: 7, Name: "Main menu"})
+
+	resolver := v1.NewRoutingResolver(fake.Client())
+	tests := []struct {
+		routing  string
+		expected string
+	}{
+		{"account:100000_office", "Sub-account 100000_office (Office)"},
+		{"fwd:1234", "Forwarding 5145559999"},
+		{"grp:42", "Ring Group Sales"},
+		{"ivr:7", "IVR Main menu"},
+		{"vm:101", "Voicemail 101"},
+		{"none:", "None"},
+	}
+
+	for _, test := range tests {
+		resolved, err := resolver.Resolve(context.Background(), v1.MustParseRoutingTarget(test.routing))
+		if err != nil {
+			t.Fatalf("%s: %v", test.routing, err)
+		}
+		if resolved.String() != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.routing, test.expected, resolved.String())
+		}
+	}
+
+	if resolved, _ := resolver.Resolve(context.Background(), v1.MustParseRoutingTarget("grp:42")); resolved.RingGroup == nil || resolved.RingGroup.Name != "Sales" {
+		t.Fatalf("ring group not resolved: %+v", resolved)
+	}
+
+	lists := 0
+	for _, params := range fake.Requests() {
+		if params.Get("method") == "getRingGroups" {
+			lists++
+		}
+	}
+	if lists != 1 {
+		t.Fatalf("expected ring groups to be listed once, got %d", lists)
+	}
+
+	if _, err := fake.Client().ResolveRouting(v1.MustParseRoutingTarget("grp:99")); err == nil {
+		t.Fatal("expected an error for an unknown ring group")
+	}
+}
+
+func TestResolveRoutingEmptyLists(t *testing.T) {
+	resolver := v1.NewRoutingResolver(newFake(t).Client())
+	tests := []struct {
+		routing  string
+		expected string
+	}{
+		{"fwd:1234", "couldn't find forwarding 1234"},
+		{"grp:1234", "couldn't find ring group 1234"},
+		{"ivr:1234", "couldn't find ivr 1234"},
+	}
+
+	for _, test := range tests {
+		_, err := resolver.Resolve(context.Background(), v1.MustParseRoutingTarget(test.routing))
+		var apiError *v1.APIError
+		if err == nil || errors.As(err, &apiError) || err.Error() != test.expected {
+			t.Errorf("%s: expected %q, got %v", test.routing, test.expected, err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const statusSuccess = "success"
+
+// APIError is returned when VoIP.ms answers with a status other than "success".
+type APIError struct {
+	Method  string
+	Status  string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Method == "" {
+		return fmt.Sprintf("voip.ms: %s: %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("voip.ms %s: %s: %s", e.Method, e.Status, e.Message)
+}
+
+// Is matches any APIError carrying the same status, so the sentinel values
+// below can be used with errors.Is regardless of the method that failed.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	return t.Status == e.Status
+}
+
+func newStatusError(status string, message string) *APIError {
+	apiError := &APIError{Status: status, Message: message}
+	statusErrors[status] = apiError
+	return apiError
+}
+
+var statusErrors = map[string]*APIError{}
+
+var (
+	ErrAPINotEnabled      = newStatusError("api_not_enabled", "API has not been enabled or has been disabled")
+	ErrIPNotEnabled       = newStatusError("ip_not_enabled", "This IP is not enabled for API use")
+	ErrInvalidCredentials = newStatusError("invalid_credentials", "Username or Password is incorrect")
+	ErrMissingCredentials = newStatusError("missing_credentials", "Username or Password was not provided")
+	ErrInvalidMethod      = newStatusError("invalid_method", "This is not a valid Method")
+	ErrMissingMethod      = newStatusError("missing_method", "Method must be provided when using the REST/JSON API")
+	ErrLimitReached       = newStatusError("limit_reached", "You have reached the maximum number of requests allowed")
+	ErrNoAccount          = newStatusError("no_account", "There are no accounts")
+	ErrInvalidAccount     = newStatusError("invalid_account", "This is not a valid account")
+	ErrMissingAccount     = newStatusError("missing_account", "Account was not provided")
+	ErrNoClient           = newStatusError("no_client", "There are no clients")
+	ErrInvalidClient      = newStatusError("invalid_client", "This is not a valid client")
+	ErrMissingClient      = newStatusError("missing_client", "Client was not provided")
+	ErrNoDID              = newStatusError("no_did", "There are no DIDs")
+	ErrInvalidDID         = newStatusError("invalid_did", "This is not a valid DID")
+	ErrMissingDID         = newStatusError("missing_did", "DID was not provided")
+	ErrInvalidPOP         = newStatusError("invalid_pop", "This is not a valid POP")
+	ErrMissingPOP         = newStatusError("missing_pop", "POP was not provided")
+	ErrInvalidServerPOP   = newStatusError("invalid_server_pop", "This is not a valid Server POP")
+	ErrNoServers          = newStatusError("no_servers", "There are no servers")
+	ErrNoForwarding       = newStatusError("no_forwarding", "There are no forwardings")
+	ErrNoRingGroup        = newStatusError("no_ring_group", "There are no ring groups")
+	ErrNoIVR              = newStatusError("no_ivr", "There are no IVRs")
+	ErrInvalidDateRange   = newStatusError("invalid_daterange", "Date range must be within the allowed limit")
+	ErrInvalidDate        = newStatusError("invalid_date", "This is not a valid date")
+	ErrNoCDR              = newStatusError("no_cdr", "There are no CDR entries for the filter")
+	ErrNoSMS              = newStatusError("no_sms", "There are no SMS messages")
+	ErrNoMMS              = newStatusError("no_mms", "There are no MMS messages")
+	ErrInvalidDst         = newStatusError("invalid_dst", "This is not a valid destination number")
+	ErrMissingDst         = newStatusError("missing_dst", "Destination number was not provided")
+	ErrSMSTooLong         = newStatusError("sms_toolong", "The SMS message exceeds 160 characters")
+	ErrMMSTooLong         = newStatusError("mms_toolong", "The MMS message exceeds 2048 characters")
+	ErrSMSFailed          = newStatusError("sms_failed", "The SMS message was not sent")
+	ErrMMSFailed          = newStatusError("mms_failed", "The MMS message was not sent")
+	ErrSMSNotAvailable    = newStatusError("sms_not_available", "SMS is not available for this DID")
+	ErrInvalidProvince    = newStatusError("invalid_province", "This is not a valid province")
+	ErrInvalidState       = newStatusError("invalid_state", "This is not a valid state")
+	ErrInvalidType        = newStatusError("invalid_type", "This is not a valid search type")
+	ErrMissingQuery       = newStatusError("missing_query", "Query was not provided")
+	ErrDIDUnavailable     = newStatusError("did_unavailable", "This DID is not available for ordering")
+	ErrMissingParams      = newStatusError("missing_params", "Required parameters were not provided")
+	ErrNotEnoughBalance   = newStatusError("not_enough_balance", "There is not enough balance on the account")
+	ErrUnavailableInfo    = newStatusError("unavailable_info", "The information requested is unavailable")
+	ErrUnknownError       = newStatusError("unknown_error", "An unknown error occurred")
+)
+
+func statusError(apiMethod string, response *BaseResponse) error {
+	if response.Status == statusSuccess || response.Status == "" {
+		return nil
+	}
+
+	message := response.Message
+	if message == "" {
+		if known, ok := statusErrors[response.Status]; ok {
+			message = known.Message
+		} else {
+			message = "unknown status"
+		}
+	}
+
+	return &APIError{
+		Method:  apiMethod,
+		Status:  response.Status,
+		Message: message,
+	}
+}
+
+func checkResponseStatus(apiMethod string, data *[]byte) error {
+	response := &BaseResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		// Leave malformed payloads for the Parse* functions to report.
+		return nil
+	}
+	return statusError(apiMethod, response)
+}
+
+// HTTPError is returned when the API endpoint answers with an HTTP error
+// status instead of a JSON payload.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("voip.ms: unexpected HTTP status %s", e.Status)
+}
+
+// ValidationError is returned before any HTTP call when a request is
+// missing a required field or holds an invalid value.
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+// requireFields takes name and value pairs and reports the first empty one.
+func requireFields(namesAndValues ...string) error {
+	for i := 0; i+1 < len(namesAndValues); i += 2 {
+		if namesAndValues[i+1] == "" {
+			return &ValidationError{Field: namesAndValues[i], Reason: "is required"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package v1_test
+
+import (
+	"errors"
+	"testing"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func TestInvalidCredentials(t *testing.T) {
+	_, vms := newStub(t)
+	vms.ApiPassword = "wrong"
+
+	_, err := vms.GetServersInfo()
+	if !errors.Is(err, v1.ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	var apiError *v1.APIError
+	if !errors.As(err, &apiError) || apiError.Method != "getServersInfo" {
+		t.Fatalf("expected APIError for getServersInfo, got %#v", err)
+	}
+}
+
+func TestUnknownStatusIsAPIError(t *testing.T) {
+	stub, vms := newStub(t)
+	stub.failNext("something_new")
+
+	_, err := vms.GetServersInfo()
+
+	var apiError *v1.APIError
+	if !errors.As(err, &apiError) || apiError.Status != "something_new" {
+		t.Fatalf("expected APIError with status something_new, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	url2 "net/url"
+	"reflect"
+)
+
+// Forwarding is a call forwarding to an external number, the target of
+// fwd: routings.
+type Forwarding struct {
+	Forwarding       VoIpMsStringInt `json:"forwarding"`
+	PhoneNumber      string          `json:"phone_number"`
+	CallerIDOverride string          `json:"callerid_override"`
+	Description      string          `json:"description"`
+	DTMFDigits       string          `json:"dtmf_digits"`
+	Pause            VoIpMsStringInt `json:"pause"`
+}
+
+// RingGroup rings several destinations at once, the target of grp:
+// routings. Members is the semicolon separated list of routings rung.
+type RingGroup struct {
+	RingGroup      VoIpMsStringInt `json:"ring_group"`
+	Name           string          `json:"name"`
+	Members        string          `json:"members"`
+	Voicemail      string          `json:"voicemail"`
+	CallerAnnounce VoIpMsStringInt `json:"caller_announcement"`
+	MusicOnHold    string          `json:"music_on_hold"`
+	Language       string          `json:"language"`
+}
+
+// IVR is an interactive voice menu, the target of ivr: routings.
+type IVR struct {
+	IVR            VoIpMsStringInt `json:"ivr"`
+	Name           string          `json:"name"`
+	Recording      string          `json:"recording"`
+	Timeout        VoIpMsStringInt `json:"timeout"`
+	Language       string          `json:"language"`
+	VoicemailSetup string          `json:"voicemailsetup"`
+	Choices        string          `json:"choices"`
+}
+
+type GetForwardingsRequest struct {
+	BaseRequest
+	Forwarding VoIpMsStringInt `url:"forwarding,omitempty"`
+}
+
+func (r *GetForwardingsRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type GetRingGroupsRequest struct {
+	BaseRequest
+	RingGroup VoIpMsStringInt `url:"ring_group,omitempty"`
+}
+
+func (r *GetRingGroupsRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type GetIVRsRequest struct {
+	BaseRequest
+	IVR VoIpMsStringInt `url:"ivr,omitempty"`
+}
+
+func (r *GetIVRsRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type GetForwardingsResponse struct {
+	BaseResponse
+	Forwardings []Forwarding `json:"forwardings"`
+}
+
+type GetRingGroupsResponse struct {
+	BaseResponse
+	RingGroups []RingGroup `json:"ring_groups"`
+}
+
+type GetIVRsResponse struct {
+	BaseResponse
+	IVRs []IVR `json:"ivrs"`
+}
+
+func ParseGetForwardings(data *[]byte) (*GetForwardingsResponse, error) {
+	response := &GetForwardingsResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func ParseGetRingGroups(data *[]byte) (*GetRingGroupsResponse, error) {
+	response := &GetRingGroupsResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func ParseGetIVRs(data *[]byte) (*GetIVRsResponse, error) {
+	response := &GetIVRsResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetForwardings lists the call forwardings, or only the one given when
+// forwarding is not zero.
+func (vms *VoIpMsApi) GetForwardings(forwarding VoIpMsStringInt) (*GetForwardingsResponse, error) {
+	return vms.GetForwardingsContext(context.Background(), forwarding)
+}
+
+func (vms *VoIpMsApi) GetForwardingsContext(ctx context.Context, forwarding VoIpMsStringInt) (*GetForwardingsResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getForwardings", &GetForwardingsRequest{
+		Forwarding: forwarding,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseGetForwardings(data)
+}
+
+// GetRingGroups lists the ring groups, or only the one given when ringGroup
+// is not zero.
+func (vms *VoIpMsApi) GetRingGroups(ringGroup VoIpMsStringInt) (*GetRingGroupsResponse, error) {
+	return vms.GetRingGroupsContext(context.Background(), ringGroup)
+}
+
+func (vms *VoIpMsApi) GetRingGroupsContext(ctx context.Context, ringGroup VoIpMsStringInt) (*GetRingGroupsResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getRingGroups", &GetRingGroupsRequest{
+		RingGroup: ringGroup,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseGetRingGroups(data)
+}
+
+// GetIVRs lists the IVRs, or only the one given when ivr is not zero.
+func (vms *VoIpMsApi) GetIVRs(ivr VoIpMsStringInt) (*GetIVRsResponse, error) {
+	return vms.GetIVRsContext(context.Background(), ivr)
+}
+
+func (vms *VoIpMsApi) GetIVRsContext(ctx context.Context, ivr VoIpMsStringInt) (*GetIVRsResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getIVRs", &GetIVRsRequest{
+		IVR: ivr,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseGetIVRs(data)
+}
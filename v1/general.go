@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -46,11 +47,15 @@ func ParseGetServersInfo(data *[]byte) (*GetServersInfoResponse, error) {
 }
 
 func (vms *VoIpMsApi) GetServersInfo() (*GetServersInfoResponse, error) {
+	return vms.GetServersInfoContext(context.Background())
+}
+
+func (vms *VoIpMsApi) GetServersInfoContext(ctx context.Context) (*GetServersInfoResponse, error) {
 	var (
 		err  error
 		data *[]byte
 	)
-	data, err = vms.NewHttpRequest(http.MethodGet, "getServersInfo", &GetServersInfo{})
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getServersInfo", &GetServersInfo{})
 	if err != nil {
 		return nil, err
 	}
@@ -59,12 +64,16 @@ func (vms *VoIpMsApi) GetServersInfo() (*GetServersInfoResponse, error) {
 }
 
 func (vms *VoIpMsApi) GetServersInfoForPopHostname(serverPopHostname string) (*ServerInfo, error) {
+	return vms.GetServersInfoForPopHostnameContext(context.Background(), serverPopHostname)
+}
+
+func (vms *VoIpMsApi) GetServersInfoForPopHostnameContext(ctx context.Context, serverPopHostname string) (*ServerInfo, error) {
 	var (
 		err         error
 		serversList *GetServersInfoResponse
 	)
 
-	if serversList, err = vms.GetServersInfo(); err == nil {
+	if serversList, err = vms.GetServersInfoContext(ctx); err == nil {
 		for _, server := range serversList.Servers {
 			if server.ServerHostname == serverPopHostname {
 				return &server, nil
@@ -77,13 +86,17 @@ func (vms *VoIpMsApi) GetServersInfoForPopHostname(serverPopHostname string) (*S
 }
 
 func (vms *VoIpMsApi) GetServersInfoForPop(pop int) (*ServerInfo, error) {
+	return vms.GetServersInfoForPopContext(context.Background(), pop)
+}
+
+func (vms *VoIpMsApi) GetServersInfoForPopContext(ctx context.Context, pop int) (*ServerInfo, error) {
 	var (
 		err         error
 		data        *[]byte
 		serversInfo *GetServersInfoResponse
 	)
 
-	data, err = vms.NewHttpRequest(http.MethodGet, "getServersInfo", &GetServersInfo{
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getServersInfo", &GetServersInfo{
 		ServerPop: strconv.Itoa(pop),
 	})
 
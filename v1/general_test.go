@@ -0,0 +1,59 @@
+package v1_test
+
+import (
+	"errors"
+	"testing"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func TestGetServersInfo(t *testing.T) {
+	fake := newFake(t)
+
+	response, err := fake.Client().GetServersInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Servers) != 2 {
+		t.Fatalf("expected 2 servers, got %d", len(response.Servers))
+	}
+
+	if response.Servers[0].ServerHostname != "montreal1.voip.ms" || response.Servers[0].ServerPOP != 8 {
+		t.Fatalf("unexpected server %+v", response.Servers[0])
+	}
+}
+
+func TestGetServersInfoForPop(t *testing.T) {
+	fake := newFake(t)
+
+	server, err := fake.Client().GetServersInfoForPop(29)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if server.ServerName != "Toronto1" {
+		t.Fatalf("unexpected server %+v", server)
+	}
+
+	if _, err = fake.Client().GetServersInfoForPop(99); !errors.Is(err, v1.ErrInvalidServerPOP) {
+		t.Fatalf("expected ErrInvalidServerPOP, got %v", err)
+	}
+}
+
+func TestGetServersInfoForPopHostname(t *testing.T) {
+	fake := newFake(t)
+
+	server, err := fake.Client().GetServersInfoForPopHostname("montreal1.voip.ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if server.ServerPOP != 8 {
+		t.Fatalf("unexpected server %+v", server)
+	}
+
+	if _, err = fake.Client().GetServersInfoForPopHostname("nowhere.voip.ms"); err == nil {
+		t.Fatal("expected an error for an unknown hostname")
+	}
+}
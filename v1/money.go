@@ -0,0 +1,123 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	moneyDecimals = 8
+	moneyScale    = 100000000
+)
+
+// VoIpMsMoney is an exact decimal amount counted in hundred-millionths of a
+// currency unit, the precision VoIP.ms uses for balances and rates.
+type VoIpMsMoney int64
+
+func ParseMoney(s string) (VoIpMsMoney, error) {
+	var (
+		err      error
+		units    int64
+		fraction int64
+	)
+
+	text := strings.TrimSpace(s)
+	negative := strings.HasPrefix(text, "-")
+	if negative || strings.HasPrefix(text, "+") {
+		text = text[1:]
+	}
+
+	integer, decimals, _ := strings.Cut(text, ".")
+	if integer == "" && decimals == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	if integer != "" {
+		if strings.Trim(integer, "0123456789") != "" {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		if units, err = strconv.ParseInt(integer, 10, 64); err != nil || units > math.MaxInt64/moneyScale {
+			return 0, fmt.Errorf("amount %q is out of range", s)
+		}
+	}
+
+	decimals = strings.TrimRight(decimals, "0")
+	if len(decimals) > moneyDecimals {
+		return 0, fmt.Errorf("amount %q has more than %d decimals", s, moneyDecimals)
+	}
+
+	if decimals != "" {
+		if strings.Trim(decimals, "0123456789") != "" {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		fraction, _ = strconv.ParseInt(decimals+strings.Repeat("0", moneyDecimals-len(decimals)), 10, 64)
+	}
+
+	if units*moneyScale > math.MaxInt64-fraction {
+		return 0, fmt.Errorf("amount %q is out of range", s)
+	}
+
+	value := units*moneyScale + fraction
+	if negative {
+		value = -value
+	}
+
+	return VoIpMsMoney(value), nil
+}
+
+func MustParseMoney(s string) VoIpMsMoney {
+	money, err := ParseMoney(s)
+	if err != nil {
+		panic(err)
+	}
+	return money
+}
+
+// String formats the amount with at least two decimals, "12.50" or "0.0095".
+func (m VoIpMsMoney) String() string {
+	value := int64(m)
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	decimals := fmt.Sprintf("%08d", value%moneyScale)
+	decimals = strings.TrimRight(decimals, "0")
+	for len(decimals) < 2 {
+		decimals += "0"
+	}
+
+	return fmt.Sprintf("%s%d.%s", sign, value/moneyScale, decimals)
+}
+
+func (m VoIpMsMoney) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *VoIpMsMoney) UnmarshalText(text []byte) error {
+	value, err := ParseMoney(string(text))
+	if err != nil {
+		return err
+	}
+	*m = value
+	return nil
+}
+
+func (m VoIpMsMoney) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON accepts both strings and bare numbers, parsing the literal
+// digits so no precision is lost through float64.
+func (m *VoIpMsMoney) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), "\"")
+	if text == "" || text == "null" {
+		*m = 0
+		return nil
+	}
+	return m.UnmarshalText([]byte(text))
+}
@@ -0,0 +1,40 @@
+package v1_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func TestRateLimiter(t *testing.T) {
+	_, vms := newStub(t)
+	vms.Limiter = v1.NewRateLimiter(50, 1)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := vms.GetServersInfo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Fatalf("3 requests at 50/s with burst 1 took only %v", elapsed)
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	limiter := v1.NewRateLimiter(0.1, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package v1
+
+import (
+	url2 "net/url"
+	"regexp"
+	"strings"
+)
+
+const redactedText = "[REDACTED]"
+
+var credentialParamsPattern = regexp.MustCompile(`(api_username|api_password)=[^&\s"]*`)
+
+// RedactParams masks the api_username and api_password parameters wherever
+// they appear in an encoded query string or form body.
+func RedactParams(text string) string {
+	return credentialParamsPattern.ReplaceAllString(text, "${1}="+redactedText)
+}
+
+// Redact masks the client credentials, raw or URL-encoded, anywhere in text.
+func (vms *VoIpMsApi) Redact(text string) string {
+	text = RedactParams(text)
+
+	for _, secret := range []string{vms.ApiPassword, vms.ApiUsername} {
+		if secret == "" {
+			continue
+		}
+		text = strings.ReplaceAll(text, secret, redactedText)
+		text = strings.ReplaceAll(text, url2.QueryEscape(secret), redactedText)
+		text = strings.ReplaceAll(text, url2.PathEscape(secret), redactedText)
+	}
+
+	return text
+}
+
+// redactedError hides credentials from Error() while keeping the original
+// error reachable through errors.Is and errors.As.
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+func (vms *VoIpMsApi) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	message := err.Error()
+	if redacted := vms.Redact(message); redacted != message {
+		return &redactedError{message: redacted, err: err}
+	}
+
+	return err
+}
@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how NewHttpRequest retries transient failures. Only
+// calls made with http.MethodGet are retried unless the API method is listed
+// in MutatingMethods.
+type RetryPolicy struct {
+	MaxAttempts          int
+	InitialBackoff       time.Duration
+	MaxBackoff           time.Duration
+	Multiplier           float64
+	Jitter               float64
+	RetryableStatusCodes []int
+	RetryableAPIStatuses []string
+	RetryableError       func(err error) bool
+	MutatingMethods      []string
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.5,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryableAPIStatuses: []string{
+			ErrLimitReached.Status,
+		},
+		RetryableError: IsTransientError,
+	}
+}
+
+// IsTransientError reports whether err is a network failure worth retrying.
+func IsTransientError(err error) bool {
+	var netError net.Error
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.As(err, &netError) && netError.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+func (p *RetryPolicy) canRetryMethod(httpMethod string, apiMethod string) bool {
+	if httpMethod == http.MethodGet {
+		return true
+	}
+
+	for _, method := range p.MutatingMethods {
+		if method == apiMethod {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *RetryPolicy) isRetryable(err error) bool {
+	var (
+		apiError  *APIError
+		httpError *HTTPError
+	)
+
+	if errors.As(err, &apiError) {
+		for _, status := range p.RetryableAPIStatuses {
+			if status == apiError.Status {
+				return true
+			}
+		}
+		return false
+	}
+
+	if errors.As(err, &httpError) {
+		for _, code := range p.RetryableStatusCodes {
+			if code == httpError.StatusCode {
+				return true
+			}
+		}
+		return false
+	}
+
+	return p.RetryableError != nil && p.RetryableError(err)
+}
+
+func (p *RetryPolicy) shouldRetry(ctx context.Context, attempt int, httpMethod string, apiMethod string, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+
+	return p.canRetryMethod(httpMethod, apiMethod) && p.isRetryable(err)
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay -= delay * jitter * rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
+
+func (p *RetryPolicy) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(p.backoff(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,82 @@
+package v1_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func TestRetryGetOnTransientErrors(t *testing.T) {
+	stub, vms := newStub(t)
+	stub.failNextHTTP(http.StatusServiceUnavailable)
+	stub.failNext(v1.ErrLimitReached.Status)
+
+	vms.Retry = v1.DefaultRetryPolicy()
+	vms.Retry.InitialBackoff = time.Millisecond
+
+	if _, err := vms.GetServersInfo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stub.requests()) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(stub.requests()))
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	stub, vms := newStub(t)
+	for i := 0; i < 3; i++ {
+		stub.failNextHTTP(http.StatusBadGateway)
+	}
+
+	vms.Retry = v1.DefaultRetryPolicy()
+	vms.Retry.MaxAttempts = 2
+	vms.Retry.InitialBackoff = time.Millisecond
+
+	_, err := vms.GetServersInfo()
+
+	var httpError *v1.HTTPError
+	if !errors.As(err, &httpError) || httpError.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected HTTPError 502, got %v", err)
+	}
+
+	if len(stub.requests()) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(stub.requests()))
+	}
+}
+func TestRetryWaitCancelled(t *testing.T) {
+	stub, vms := newStub(t)
+	stub.failNextHTTP(http.StatusServiceUnavailable)
+
+	vms.Retry = v1.DefaultRetryPolicy()
+	vms.Retry.InitialBackoff = time.Minute
+	vms.Retry.Jitter = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := vms.GetServersInfoContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRetryMutatingMethodsOptIn(t *testing.T) {
+	stub, vms := newStub(t)
+	vms.Retry = v1.DefaultRetryPolicy()
+	vms.Retry.InitialBackoff = time.Millisecond
+
+	stub.failNextHTTP(http.StatusServiceUnavailable)
+	if _, err := vms.SetDidPop("5145550100", 29); err == nil {
+		t.Fatal("setDIDPOP must not be retried by default")
+	}
+
+	vms.Retry.MutatingMethods = []string{"setDIDPOP"}
+	stub.failNextHTTP(http.StatusServiceUnavailable)
+	if _, err := vms.SetDidPop("5145550100", 29); err != nil {
+		t.Fatalf("setDIDPOP should have been retried: %v", err)
+	}
+}
@@ -0,0 +1,361 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RoutingKind is the part before the colon of a VoIP.ms routing string.
+type RoutingKind string
+
+const (
+	RoutingAccount     RoutingKind = "account"
+	RoutingForwarding  RoutingKind = "fwd"
+	RoutingVoicemail   RoutingKind = "vm"
+	RoutingSIPURI      RoutingKind = "sip"
+	RoutingRingGroup   RoutingKind = "grp"
+	RoutingIVR         RoutingKind = "ivr"
+	RoutingTimeCond    RoutingKind = "tc"
+	RoutingQueue       RoutingKind = "queue"
+	RoutingCallback    RoutingKind = "cb"
+	RoutingDISA        RoutingKind = "disa"
+	RoutingRecording   RoutingKind = "recording"
+	RoutingConference  RoutingKind = "conf"
+	RoutingCallingCard RoutingKind = "cc"
+	RoutingSystem      RoutingKind = "sys"
+	RoutingNone        RoutingKind = "none"
+)
+
+var (
+	routingAccountPattern = regexp.MustCompile(`^[0-9]+(_[A-Za-z0-9_.-]+)?$`)
+	routingIDPattern      = regexp.MustCompile(`^[0-9]+$`)
+	routingSystemPattern  = regexp.MustCompile(`^[a-z0-9]+$`)
+)
+
+var routingKindNames = map[RoutingKind]string{
+	RoutingAccount:     "Sub-account",
+	RoutingForwarding:  "Forwarding",
+	RoutingVoicemail:   "Voicemail",
+	RoutingSIPURI:      "SIP URI",
+	RoutingRingGroup:   "Ring Group",
+	RoutingIVR:         "IVR",
+	RoutingTimeCond:    "Time Condition",
+	RoutingQueue:       "Queue",
+	RoutingCallback:    "Callback",
+	RoutingDISA:        "DISA",
+	RoutingRecording:   "Recording",
+	RoutingConference:  "Conference",
+	RoutingCallingCard: "Calling Card",
+	RoutingSystem:      "System",
+	RoutingNone:        "None",
+}
+
+// RoutingTarget is where a DID sends its calls, written type:value by
+// VoIP.ms, such as account:100000_office, grp:1234 or sys:hangup. The zero
+// value stands for an unset routing. A routing decoded from text that is not
+// type:value keeps that text in Value with an empty Kind, and fails Validate.
+type RoutingTarget struct {
+	Kind  RoutingKind
+	Value string
+}
+
+// ParseRoutingTarget reads a VoIP.ms routing string. An empty string gives
+// the zero RoutingTarget.
+func ParseRoutingTarget(text string) (RoutingTarget, error) {
+	if text == "" {
+		return RoutingTarget{}, nil
+	}
+
+	kind, value, ok := strings.Cut(text, ":")
+	if !ok || kind == "" {
+		return RoutingTarget{}, fmt.Errorf("invalid routing %q, expecting type:value", text)
+	}
+
+	return RoutingTarget{Kind: RoutingKind(kind), Value: value}, nil
+}
+
+func MustParseRoutingTarget(text string) RoutingTarget {
+	target, err := ParseRoutingTarget(text)
+	if err != nil {
+		panic(err)
+	}
+	return target
+}
+
+func (t RoutingTarget) IsZero() bool {
+	return t.Kind == "" && t.Value == ""
+}
+
+func (t RoutingTarget) String() string {
+	if t.Kind == "" {
+		return t.Value
+	}
+	return string(t.Kind) + ":" + t.Value
+}
+
+// Validate checks the value has the form VoIP.ms expects for its kind: an
+// account name for account:, a numeric ID for most others and nothing at all
+// for none:.
+func (t RoutingTarget) Validate() error {
+	return t.validate("routing")
+}
+
+func (t RoutingTarget) validate(field string) error {
+	var pattern *regexp.Regexp
+
+	switch t.Kind {
+	case RoutingNone:
+		if t.Value != "" {
+			return &ValidationError{Field: field, Reason: "none: takes no value"}
+		}
+		return nil
+	case RoutingAccount:
+		pattern = routingAccountPattern
+	case RoutingSystem:
+		pattern = routingSystemPattern
+	case "":
+		if t.Value != "" {
+			return &ValidationError{Field: field, Reason: fmt.Sprintf("invalid routing %q, expecting type:value", t.Value)}
+		}
+		return &ValidationError{Field: field, Reason: "is required"}
+	default:
+		if _, ok := routingKindNames[t.Kind]; !ok {
+			return &ValidationError{Field: field, Reason: fmt.Sprintf("unknown routing type %q", t.Kind)}
+		}
+		pattern = routingIDPattern
+	}
+
+	if !pattern.MatchString(t.Value) {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("%q is not a valid %s value", t.Value, t.Kind)}
+	}
+
+	return nil
+}
+
+func (t RoutingTarget) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText never fails so one unexpected routing does not prevent
+// decoding a whole list of DIDs; Validate reports it instead.
+func (t *RoutingTarget) UnmarshalText(text []byte) error {
+	target, err := ParseRoutingTarget(string(text))
+	if err != nil {
+		target = RoutingTarget{Value: string(text)}
+	}
+	*t = target
+	return nil
+}
+
+// ResolvedRouting is a RoutingTarget along with the object it points to,
+// when that object can be looked up through the API.
+type ResolvedRouting struct {
+	Target     RoutingTarget
+	SubAccount *SubAccount
+	Forwarding *Forwarding
+	RingGroup  *RingGroup
+	IVR        *IVR
+}
+
+// String describes the routing for humans, such as "Ring Group Sales".
+func (r *ResolvedRouting) String() string {
+	kind, ok := routingKindNames[r.Target.Kind]
+	if !ok {
+		return r.Target.String()
+	}
+
+	switch {
+	case r.Target.Kind == RoutingNone:
+		return kind
+	case r.SubAccount != nil && r.SubAccount.Description != "":
+		return fmt.Sprintf("%s %s (%s)", kind, r.SubAccount.Account, r.SubAccount.Description)
+	case r.Forwarding != nil && r.Forwarding.Description != "":
+		return fmt.Sprintf("%s %s (%s)", kind, r.Forwarding.PhoneNumber, r.Forwarding.Description)
+	case r.Forwarding != nil:
+		return fmt.Sprintf("%s %s", kind, r.Forwarding.PhoneNumber)
+	case r.RingGroup != nil:
+		return fmt.Sprintf("%s %s", kind, r.RingGroup.Name)
+	case r.IVR != nil:
+		return fmt.Sprintf("%s %s", kind, r.IVR.Name)
+	}
+
+	return fmt.Sprintf("%s %s", kind, r.Target.Value)
+}
+
+// RoutingResolver looks up the sub-accounts, forwardings, ring groups and
+// IVRs routings point to. Each list is fetched once and kept, so resolving
+// the routings of many DIDs costs at most one call per kind. It is safe for
+// concurrent use; fetching one list does not hold up lookups in the others.
+type RoutingResolver struct {
+	vms *VoIpMsApi
+
+	subAccountsMutex sync.Mutex
+	subAccounts      map[string]*SubAccount
+	forwardingsMutex sync.Mutex
+	forwardings      map[string]*Forwarding
+	ringGroupsMutex  sync.Mutex
+	ringGroups       map[string]*RingGroup
+	ivrsMutex        sync.Mutex
+	ivrs             map[string]*IVR
+}
+
+func NewRoutingResolver(vms *VoIpMsApi) *RoutingResolver {
+	return &RoutingResolver{vms: vms}
+}
+
+// Resolve looks up the object target points to. Kinds without a lookup are
+// returned as is; a missing object is an error.
+func (r *RoutingResolver) Resolve(ctx context.Context, target RoutingTarget) (*ResolvedRouting, error) {
+	var (
+		err      error
+		found    bool
+		resolved = &ResolvedRouting{Target: target}
+	)
+
+	// The lists are never changed once loaded, so they are read unlocked.
+	switch target.Kind {
+	case RoutingAccount:
+		var subAccounts map[string]*SubAccount
+		if subAccounts, err = r.loadSubAccounts(ctx); err == nil {
+			resolved.SubAccount, found = subAccounts[target.Value]
+		}
+	case RoutingForwarding:
+		var forwardings map[string]*Forwarding
+		if forwardings, err = r.loadForwardings(ctx); err == nil {
+			resolved.Forwarding, found = forwardings[target.Value]
+		}
+	case RoutingRingGroup:
+		var ringGroups map[string]*RingGroup
+		if ringGroups, err = r.loadRingGroups(ctx); err == nil {
+			resolved.RingGroup, found = ringGroups[target.Value]
+		}
+	case RoutingIVR:
+		var ivrs map[string]*IVR
+		if ivrs, err = r.loadIVRs(ctx); err == nil {
+			resolved.IVR, found = ivrs[target.Value]
+		}
+	default:
+		return resolved, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("couldn't find %s %s", strings.ToLower(routingKindNames[target.Kind]), target.Value)
+	}
+
+	return resolved, nil
+}
+
+func (r *RoutingResolver) loadSubAccounts(ctx context.Context) (map[string]*SubAccount, error) {
+	r.subAccountsMutex.Lock()
+	defer r.subAccountsMutex.Unlock()
+
+	if r.subAccounts != nil {
+		return r.subAccounts, nil
+	}
+
+	response, err := r.vms.GetSubAccountsContext(ctx, "")
+	if err != nil && !errors.Is(err, ErrNoAccount) {
+		return nil, err
+	}
+
+	subAccounts := map[string]*SubAccount{}
+	if response != nil {
+		for i := range response.Accounts {
+			subAccounts[response.Accounts[i].Account] = &response.Accounts[i]
+		}
+	}
+
+	r.subAccounts = subAccounts
+	return subAccounts, nil
+}
+
+func (r *RoutingResolver) loadForwardings(ctx context.Context) (map[string]*Forwarding, error) {
+	r.forwardingsMutex.Lock()
+	defer r.forwardingsMutex.Unlock()
+
+	if r.forwardings != nil {
+		return r.forwardings, nil
+	}
+
+	response, err := r.vms.GetForwardingsContext(ctx, 0)
+	if err != nil && !errors.Is(err, ErrNoForwarding) {
+		return nil, err
+	}
+
+	forwardings := map[string]*Forwarding{}
+	if response != nil {
+		for i := range response.Forwardings {
+			forwardings[strconv.FormatInt(int64(response.Forwardings[i].Forwarding), 10)] = &response.Forwardings[i]
+		}
+	}
+
+	r.forwardings = forwardings
+	return forwardings, nil
+}
+
+func (r *RoutingResolver) loadRingGroups(ctx context.Context) (map[string]*RingGroup, error) {
+	r.ringGroupsMutex.Lock()
+	defer r.ringGroupsMutex.Unlock()
+
+	if r.ringGroups != nil {
+		return r.ringGroups, nil
+	}
+
+	response, err := r.vms.GetRingGroupsContext(ctx, 0)
+	if err != nil && !errors.Is(err, ErrNoRingGroup) {
+		return nil, err
+	}
+
+	ringGroups := map[string]*RingGroup{}
+	if response != nil {
+		for i := range response.RingGroups {
+			ringGroups[strconv.FormatInt(int64(response.RingGroups[i].RingGroup), 10)] = &response.RingGroups[i]
+		}
+	}
+
+	r.ringGroups = ringGroups
+	return ringGroups, nil
+}
+
+func (r *RoutingResolver) loadIVRs(ctx context.Context) (map[string]*IVR, error) {
+	r.ivrsMutex.Lock()
+	defer r.ivrsMutex.Unlock()
+
+	if r.ivrs != nil {
+		return r.ivrs, nil
+	}
+
+	response, err := r.vms.GetIVRsContext(ctx, 0)
+	if err != nil && !errors.Is(err, ErrNoIVR) {
+		return nil, err
+	}
+
+	ivrs := map[string]*IVR{}
+	if response != nil {
+		for i := range response.IVRs {
+			ivrs[strconv.FormatInt(int64(response.IVRs[i].IVR), 10)] = &response.IVRs[i]
+		}
+	}
+
+	r.ivrs = ivrs
+	return ivrs, nil
+}
+
+// ResolveRouting looks up the object target points to, see RoutingResolver
+// to resolve many routings.
+func (vms *VoIpMsApi) ResolveRouting(target RoutingTarget) (*ResolvedRouting, error) {
+	return vms.ResolveRoutingContext(context.Background(), target)
+}
+
+func (vms *VoIpMsApi) ResolveRoutingContext(ctx context.Context, target RoutingTarget) (*ResolvedRouting, error) {
+	return NewRoutingResolver(vms).Resolve(ctx, target)
+}
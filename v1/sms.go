@@ -0,0 +1,398 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	url2 "net/url"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// SMSMaxLength is the longest message, in characters, sendSMS accepts.
+	SMSMaxLength = 160
+	// MMSMaxLength is the longest message, in characters, sendMMS accepts.
+	MMSMaxLength = 2048
+	// MMSMaxMedia is the number of attachments sendMMS accepts.
+	MMSMaxMedia = 3
+)
+
+type MessageType int64
+
+const (
+	MessageSent     MessageType = 0
+	MessageReceived MessageType = 1
+)
+
+func (t MessageType) String() string {
+	switch t {
+	case MessageSent:
+		return "sent"
+	case MessageReceived:
+		return "received"
+	default:
+		return fmt.Sprintf("message type %d", int64(t))
+	}
+}
+
+func (t MessageType) MarshalJSON() ([]byte, error) {
+	return VoIpMsStringInt(t).MarshalJSON()
+}
+
+func (t *MessageType) UnmarshalJSON(data []byte) error {
+	return (*VoIpMsStringInt)(t).UnmarshalJSON(data)
+}
+
+// Message is an SMS or an MMS. Media holds the attachment URLs of an MMS.
+type Message struct {
+	ID      VoIpMsStringInt `json:"id"`
+	Date    VoIpMsDateTime  `json:"date"`
+	Type    MessageType     `json:"type"`
+	DID     string          `json:"did"`
+	Contact string          `json:"contact"`
+	Message string          `json:"message"`
+	Media   []string        `json:"media,omitempty"`
+}
+
+// MessageFilter selects the messages returned by GetSMS and GetMMS. Dates are
+// expressed in the Location of the client unless Timezone, hours from UTC, is
+// set.
+type MessageFilter struct {
+	From     VoIpMsDate   `url:"from,omitempty"`
+	To       VoIpMsDate   `url:"to,omitempty"`
+	Type     *MessageType `url:"type"`
+	DID      string       `url:"did,omitempty"`
+	Contact  string       `url:"contact,omitempty"`
+	Limit    int          `url:"limit,omitempty"`
+	Timezone *float64     `url:"timezone"`
+}
+
+type SendSMSRequest struct {
+	BaseRequest
+	DID     string `url:"did"`
+	Dst     string `url:"dst"`
+	Message string `url:"message"`
+}
+
+func (r *SendSMSRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type SendMMSRequest struct {
+	BaseRequest
+	DID     string `url:"did"`
+	Dst     string `url:"dst"`
+	Message string `url:"message"`
+	Media1  string `url:"media1,omitempty"`
+	Media2  string `url:"media2,omitempty"`
+	Media3  string `url:"media3,omitempty"`
+}
+
+func (r *SendMMSRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type GetSMSRequest struct {
+	BaseRequest
+	MessageFilter
+	ID VoIpMsStringInt `url:"sms,omitempty"`
+}
+
+func (r *GetSMSRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type GetMMSRequest struct {
+	BaseRequest
+	MessageFilter
+	ID           VoIpMsStringInt `url:"mms,omitempty"`
+	MediaAsArray bool            `url:"media_as_array"`
+}
+
+func (r *GetMMSRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type DeleteSMSRequest struct {
+	BaseRequest
+	ID VoIpMsStringInt `url:"id"`
+}
+
+func (r *DeleteSMSRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type SendSMSResponse struct {
+	BaseResponse
+	SMS VoIpMsStringInt `json:"sms"`
+}
+
+type SendMMSResponse struct {
+	BaseResponse
+	MMS VoIpMsStringInt `json:"mms"`
+}
+
+// SendMessageResult lists the ID of every segment sent, in order.
+type SendMessageResult struct {
+	IDs []VoIpMsStringInt
+}
+
+// GetMessagesResponse is returned by both getSMS and getMMS, which list
+// messages under the same key.
+type GetMessagesResponse struct {
+	BaseResponse
+	Messages []Message `json:"sms"`
+}
+
+func ParseSendSMS(data *[]byte) (*SendSMSResponse, error) {
+	response := &SendSMSResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func ParseSendMMS(data *[]byte) (*SendMMSResponse, error) {
+	response := &SendMMSResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ParseGetMessages decodes a getSMS or getMMS response with its dates read as
+// UTC, whatever timezone the messages were requested in.
+func ParseGetMessages(data *[]byte) (*GetMessagesResponse, error) {
+	response := &GetMessagesResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// SplitMessage cuts message into segments of at most limit characters,
+// breaking on the last whitespace of each segment when there is one. A limit
+// of zero or less uses SMSMaxLength. Segments holding only whitespace are
+// dropped, a blank message giving a single empty segment.
+func SplitMessage(message string, limit int) []string {
+	var segments []string
+
+	if limit <= 0 {
+		limit = SMSMaxLength
+	}
+
+	runes := []rune(message)
+	for len(runes) > limit {
+		cut := limit
+		for i := limit; i > 0; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+
+		if segment := strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace); segment != "" {
+			segments = append(segments, segment)
+		}
+		runes = []rune(strings.TrimLeftFunc(string(runes[cut:]), unicode.IsSpace))
+	}
+
+	if strings.TrimSpace(string(runes)) != "" {
+		segments = append(segments, string(runes))
+	} else if len(segments) == 0 {
+		segments = append(segments, "")
+	}
+
+	return segments
+}
+
+// SendSMS sends message from did to dst, split in as many SMS as needed. On
+// error, the result holds the segments already sent.
+func (vms *VoIpMsApi) SendSMS(did string, dst string, message string) (*SendMessageResult, error) {
+	return vms.SendSMSContext(context.Background(), did, dst, message)
+}
+
+func (vms *VoIpMsApi) SendSMSContext(ctx context.Context, did string, dst string, message string) (*SendMessageResult, error) {
+	var (
+		err      error
+		data     *[]byte
+		response *SendSMSResponse
+		result   = &SendMessageResult{}
+	)
+
+	if err = requireFields("did", did, "dst", dst, "message", message); err != nil {
+		return nil, err
+	}
+
+	for _, segment := range SplitMessage(message, SMSMaxLength) {
+		data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "sendSMS", &SendSMSRequest{
+			DID:     did,
+			Dst:     dst,
+			Message: segment,
+		})
+
+		if err != nil {
+			return result, err
+		}
+
+		if response, err = ParseSendSMS(data); err != nil {
+			return result, err
+		}
+
+		result.IDs = append(result.IDs, response.SMS)
+	}
+
+	return result, nil
+}
+
+// SendMMS sends message and up to MMSMaxMedia attachments from did to dst.
+// Media are URLs or base64 data URIs and go with the first segment when the
+// message has to be split.
+func (vms *VoIpMsApi) SendMMS(did string, dst string, message string, media []string) (*SendMessageResult, error) {
+	return vms.SendMMSContext(context.Background(), did, dst, message, media)
+}
+
+func (vms *VoIpMsApi) SendMMSContext(ctx context.Context, did string, dst string, message string, media []string) (*SendMessageResult, error) {
+	var (
+		err      error
+		data     *[]byte
+		response *SendMMSResponse
+		result   = &SendMessageResult{}
+	)
+
+	if err = requireFields("did", did, "dst", dst); err != nil {
+		return nil, err
+	}
+
+	if len(media) > MMSMaxMedia {
+		return nil, &ValidationError{Field: "media", Reason: fmt.Sprintf("at most %d attachments are allowed", MMSMaxMedia)}
+	}
+
+	if message == "" && len(media) == 0 {
+		return nil, &ValidationError{Field: "message", Reason: "is required without media"}
+	}
+
+	attachments := make([]string, MMSMaxMedia)
+	copy(attachments, media)
+
+	for _, segment := range SplitMessage(message, MMSMaxLength) {
+		data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "sendMMS", &SendMMSRequest{
+			DID:     did,
+			Dst:     dst,
+			Message: segment,
+			Media1:  attachments[0],
+			Media2:  attachments[1],
+			Media3:  attachments[2],
+		})
+
+		if err != nil {
+			return result, err
+		}
+
+		if response, err = ParseSendMMS(data); err != nil {
+			return result, err
+		}
+
+		result.IDs = append(result.IDs, response.MMS)
+		attachments = make([]string, MMSMaxMedia)
+	}
+
+	return result, nil
+}
+
+func (vms *VoIpMsApi) getMessages(ctx context.Context, apiMethod string, filter *MessageFilter, request RequestParams) (*GetMessagesResponse, error) {
+	var (
+		err      error
+		data     *[]byte
+		response *GetMessagesResponse
+	)
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From.Time) {
+		return nil, &ValidationError{Field: "to", Reason: "is before from"}
+	}
+
+	// filter is the one embedded in request: setting its timezone asks for
+	// the dates in the zone they are read in below.
+	day := filter.From.Time
+	if day.IsZero() {
+		day = time.Now()
+	}
+	timezone := vms.timezone(filter.Timezone, day)
+	filter.Timezone = &timezone
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, apiMethod, request)
+	if errors.Is(err, ErrNoSMS) || errors.Is(err, ErrNoMMS) {
+		return &GetMessagesResponse{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if response, err = ParseGetMessages(data); err != nil {
+		return nil, err
+	}
+
+	location := timezoneLocation(timezone)
+	for i := range response.Messages {
+		response.Messages[i].Date.Time = inLocation(response.Messages[i].Date.Time, location)
+	}
+
+	return response, nil
+}
+
+// GetSMS lists the SMS matching filter. No match gives an empty list rather
+// than ErrNoSMS.
+func (vms *VoIpMsApi) GetSMS(filter MessageFilter) (*GetMessagesResponse, error) {
+	return vms.GetSMSContext(context.Background(), filter)
+}
+
+func (vms *VoIpMsApi) GetSMSContext(ctx context.Context, filter MessageFilter) (*GetMessagesResponse, error) {
+	request := &GetSMSRequest{MessageFilter: filter}
+	return vms.getMessages(ctx, "getSMS", &request.MessageFilter, request)
+}
+
+// GetMMS lists the MMS matching filter along with their media URLs.
+func (vms *VoIpMsApi) GetMMS(filter MessageFilter) (*GetMessagesResponse, error) {
+	return vms.GetMMSContext(context.Background(), filter)
+}
+
+func (vms *VoIpMsApi) GetMMSContext(ctx context.Context, filter MessageFilter) (*GetMessagesResponse, error) {
+	request := &GetMMSRequest{MessageFilter: filter, MediaAsArray: true}
+	return vms.getMessages(ctx, "getMMS", &request.MessageFilter, request)
+}
+
+func (vms *VoIpMsApi) DeleteSMS(id VoIpMsStringInt) (*BaseResponse, error) {
+	return vms.DeleteSMSContext(context.Background(), id)
+}
+
+func (vms *VoIpMsApi) DeleteSMSContext(ctx context.Context, id VoIpMsStringInt) (*BaseResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if id <= 0 {
+		return nil, &ValidationError{Field: "id", Reason: "is required"}
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodDelete, "deleteSMS", &DeleteSMSRequest{ID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBaseResponse(data)
+}
@@ -0,0 +1,224 @@
+package v1_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		message  string
+		limit    int
+		expected []string
+	}{
+		{"", 10, []string{""}},
+		{"short", 10, []string{"short"}},
+		{"hello world again", 11, []string{"hello world", "again"}},
+		{"hello world again", 10, []string{"hello", "world", "again"}},
+		{"abcdefghijkl", 5, []string{"abcde", "fghij", "kl"}},
+		{"éééé ééé", 4, []string{"éééé", "ééé"}},
+		{"ab c", 1, []string{"a", "b", "c"}},
+		{"short", 0, []string{"short"}},
+		{"short", -1, []string{"short"}},
+		{strings.Repeat("a", 161), 0, []string{strings.Repeat("a", 160), "a"}},
+		{"   ", 10, []string{""}},
+		{"   \n  ", 2, []string{""}},
+		{"  abc def", 3, []string{"abc", "def"}},
+		{"abc    def", 4, []string{"abc", "def"}},
+	}
+
+	for _, test := range tests {
+		segments := v1.SplitMessage(test.message, test.limit)
+		if strings.Join(segments, "|") != strings.Join(test.expected, "|") {
+			t.Errorf("%q/%d: expected %q, got %q", test.message, test.limit, test.expected, segments)
+		}
+	}
+}
+
+func TestSendSMSSplitsLongMessages(t *testing.T) {
+	fake := newFake(t)
+	message := strings.Repeat("lorem ipsum ", 20)
+
+	result, err := fake.Client().SendSMS("5145550100", "5145550101", message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := fake.Messages()
+	if len(result.IDs) != 2 || len(sent) != 2 {
+		t.Fatalf("expected 2 segments, got %v and %+v", result.IDs, sent)
+	}
+
+	for _, segment := range sent {
+		if utf8.RuneCountInString(segment.Message) > v1.SMSMaxLength {
+			t.Fatalf("segment too long: %q", segment.Message)
+		}
+	}
+
+	if sent[0].Message+" "+sent[1].Message != message {
+		t.Fatalf("segments do not rebuild the message: %+v", sent)
+	}
+}
+
+func TestSendSMSErrors(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	var validationError *v1.ValidationError
+	if _, err := vms.SendSMS("5145550100", "", "hi"); !errors.As(err, &validationError) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	if _, err := vms.SendSMS("5145550100", "123", "hi"); !errors.Is(err, v1.ErrInvalidDst) {
+		t.Fatalf("expected ErrInvalidDst, got %v", err)
+	}
+
+	fake.FailNext("sendSMS", "sms_failed")
+	result, err := vms.SendSMS("5145550100", "5145550101", strings.Repeat("x", 200))
+	if !errors.Is(err, v1.ErrSMSFailed) || result == nil || len(result.IDs) != 0 {
+		t.Fatalf("expected ErrSMSFailed with no segment sent, got %v, %+v", err, result)
+	}
+}
+
+func TestSendMMS(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	media := []string{"https://example.com/a.png", "data:image/png;base64,iVBORw0KGgo="}
+	if _, err := vms.SendMMS("5145550100", "5145550101", "picture", media); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := vms.GetMMS(v1.MessageFilter{DID: "5145550100"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Messages) != 1 || len(response.Messages[0].Media) != 2 || response.Messages[0].Media[1] != media[1] {
+		t.Fatalf("unexpected MMS %+v", response.Messages)
+	}
+
+	var validationError *v1.ValidationError
+	if _, err = vms.SendMMS("5145550100", "5145550101", "", nil); !errors.As(err, &validationError) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	if _, err = vms.SendMMS("5145550100", "5145550101", "", []string{"a", "b", "c", "d"}); !errors.As(err, &validationError) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+}
+
+func TestGetAndDeleteSMS(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	fake.AddMessage(v1.Message{Type: v1.MessageReceived, DID: "5145550100", Contact: "5145550101", Message: "ping"})
+	fake.AddMessage(v1.Message{Type: v1.MessageReceived, DID: "5145550100", Contact: "5145550102", Message: "other"})
+	if _, err := vms.SendSMS("5145550100", "5145550101", "pong"); err != nil {
+		t.Fatal(err)
+	}
+
+	received := v1.MessageReceived
+	response, err := vms.GetSMS(v1.MessageFilter{Contact: "5145550101", Type: &received})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Messages) != 1 || response.Messages[0].Message != "ping" || response.Messages[0].Type != v1.MessageReceived {
+		t.Fatalf("unexpected messages %+v", response.Messages)
+	}
+
+	if _, err = vms.DeleteSMS(response.Messages[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if response, err = vms.GetSMS(v1.MessageFilter{Contact: "5145550101", Type: &received}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Messages) != 0 {
+		t.Fatalf("expected no message left, got %+v", response.Messages)
+	}
+
+	params := fake.Requests()[len(fake.Requests())-1]
+	if params.Get("type") != "1" || params.Get("timezone") != "0" || params.Has("from") {
+		t.Fatalf("unexpected parameters %v", params)
+	}
+}
+
+func TestGetSMSInLocation(t *testing.T) {
+	fake := newFake(t)
+	sent := time.Date(2024, time.March, 1, 3, 30, 0, 0, time.UTC)
+	fake.AddMessage(v1.Message{Type: v1.MessageReceived, DID: "5145550100", Contact: "5145550101", Message: "late", Date: v1.VoIpMsDateTime{Time: sent}})
+
+	vms := fake.Client()
+	vms.Location = time.FixedZone("EST", -5*60*60)
+
+	response, err := vms.GetSMS(v1.MessageFilter{From: date(2024, time.February, 29), To: date(2024, time.February, 29)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Messages) != 1 || !response.Messages[0].Date.Equal(sent) || response.Messages[0].Date.Format(time.DateTime) != "2024-02-29 22:30:00" {
+		t.Fatalf("unexpected messages %+v", response.Messages)
+	}
+
+	if params := fake.Requests()[len(fake.Requests())-1]; params.Get("timezone") != "-5" {
+		t.Fatalf("unexpected timezone %q", params.Get("timezone"))
+	}
+}
+
+func TestSetSMS(t *testing.T) {
+	fake := newFake(t)
+	fake.AddDID(v1.DIDInfo{DID: "5145550110", SMSAvailable: 1, SMSEnabled: 1, SMSEmailEnabled: 1, SMSEmail: "ops@example.com"})
+	vms := fake.Client()
+
+	info, err := vms.GetDidInfo("", "5145550110")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := v1.SMSSettingsFromInfo(info)
+	settings.URLCallbackEnabled = true
+	settings.URLCallback = "https://example.com/sms?id={ID}"
+	settings.URLCallbackRetry = true
+
+	if _, err = vms.SetSMS("5145550110", settings); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, _ := fake.DID("5145550110")
+	if updated.SMSURLCallback != settings.URLCallback || updated.SMSURLCallbackRetry != 1 {
+		t.Fatalf("callback not set: %+v", updated)
+	}
+
+	if updated.SMSEmail != "ops@example.com" || updated.SMSEmailEnabled != 1 || updated.SMSEnabled != 1 {
+		t.Fatalf("settings derived from the DID were not kept: %+v", updated)
+	}
+}
+
+func TestSetSMSErrors(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	var validationError *v1.ValidationError
+	for _, settings := range []v1.SMSSettings{
+		{Enabled: true, EmailEnabled: true, Email: "nobody"},
+		{Enabled: true, ForwardEnabled: true, Forward: "+1 514"},
+		{Enabled: true, URLCallbackEnabled: true, URLCallback: "ftp://example.com"},
+		{Enabled: true, SMPPEnabled: true, SMPPURL: "smpp.example.com"},
+	} {
+		if _, err := vms.SetSMS("5145550100", settings); !errors.As(err, &validationError) {
+			t.Fatalf("expected ValidationError for %+v, got %v", settings, err)
+		}
+	}
+
+	if _, err := vms.SetSMS("5145550100", v1.SMSSettings{Enabled: true}); !errors.Is(err, v1.ErrSMSNotAvailable) {
+		t.Fatalf("expected ErrSMSNotAvailable, got %v", err)
+	}
+}
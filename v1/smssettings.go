@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	url2 "net/url"
+	"reflect"
+	"strings"
+)
+
+// SMSSettings holds the SMS routing of a DID sent to setSMS. VoIP.ms
+// replaces every setting on each call, so start from SMSSettingsFromInfo to
+// change only some of them.
+type SMSSettings struct {
+	Enabled            bool   `url:"enable"`
+	EmailEnabled       bool   `url:"email_enabled"`
+	Email              string `url:"email_address"`
+	ForwardEnabled     bool   `url:"sms_forward_enable"`
+	Forward            string `url:"sms_forward"`
+	URLCallbackEnabled bool   `url:"url_callback_enable"`
+	URLCallback        string `url:"url_callback"`
+	URLCallbackRetry   bool   `url:"url_callback_retry"`
+	SMPPEnabled        bool   `url:"smpp_enabled"`
+	SMPPURL            string `url:"smpp_url"`
+	SMPPUser           string `url:"smpp_user"`
+	SMPPPass           string `url:"smpp_pass"`
+}
+
+func SMSSettingsFromInfo(did *DIDInfo) SMSSettings {
+	return SMSSettings{
+		Enabled:            did.SMSEnabled != 0,
+		EmailEnabled:       did.SMSEmailEnabled != 0,
+		Email:              did.SMSEmail,
+		ForwardEnabled:     did.SMSForwardEnabled != 0,
+		Forward:            did.SMSForward,
+		URLCallbackEnabled: did.SMSURLCallbackEnabled != 0,
+		URLCallback:        did.SMSURLCallback,
+		URLCallbackRetry:   did.SMSURLCallbackRetry != 0,
+		SMPPEnabled:        did.SMPPE != 0,
+		SMPPURL:            did.SMPPURL,
+		SMPPUser:           did.SMPPUser,
+		SMPPPass:           did.SMPPPass,
+	}
+}
+
+// Validate checks that every enabled destination is set.
+func (s *SMSSettings) Validate() error {
+	if s.EmailEnabled {
+		if at := strings.Index(s.Email, "@"); at < 1 || at == len(s.Email)-1 {
+			return &ValidationError{Field: "email_address", Reason: "is not an email address"}
+		}
+	}
+
+	if s.ForwardEnabled {
+		if s.Forward == "" || strings.Trim(s.Forward, "0123456789") != "" {
+			return &ValidationError{Field: "sms_forward", Reason: "must be a phone number"}
+		}
+	}
+
+	if s.URLCallbackEnabled {
+		callback, err := url2.Parse(s.URLCallback)
+		if err != nil || (callback.Scheme != "http" && callback.Scheme != "https") || callback.Host == "" {
+			return &ValidationError{Field: "url_callback", Reason: "must be an http or https URL"}
+		}
+	}
+
+	if s.SMPPEnabled {
+		if err := requireFields("smpp_url", s.SMPPURL, "smpp_user", s.SMPPUser, "smpp_pass", s.SMPPPass); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type SetSMSRequest struct {
+	BaseRequest
+	DID string `url:"did"`
+	SMSSettings
+}
+
+func (r *SetSMSRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+// SetSMS replaces the SMS settings of did.
+func (vms *VoIpMsApi) SetSMS(did string, settings SMSSettings) (*BaseResponse, error) {
+	return vms.SetSMSContext(context.Background(), did, settings)
+}
+
+func (vms *VoIpMsApi) SetSMSContext(ctx context.Context, did string, settings SMSSettings) (*BaseResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if err = requireFields("did", did); err != nil {
+		return nil, err
+	}
+
+	if err = settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "setSMS", &SetSMSRequest{
+		DID:         did,
+		SMSSettings: settings,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBaseResponse(data)
+}
@@ -0,0 +1,88 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+const (
+	stubUsername = "user@example.com"
+	stubPassword = "s3cr3t-api-key"
+)
+
+// stubResponse is a canned answer: an HTTP error when code is set, otherwise
+// a JSON body carrying status.
+type stubResponse struct {
+	code   int
+	status string
+}
+
+// stub is a bare VoIP.ms endpoint answering queued responses in order, then
+// success once the queue is empty. Wrong credentials are always refused.
+type stub struct {
+	mutex     sync.Mutex
+	methods   []string
+	responses []stubResponse
+}
+
+func newStub(t *testing.T) (*stub, *v1.VoIpMsApi) {
+	t.Helper()
+
+	s := &stub{}
+	server := httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(server.Close)
+
+	vms := &v1.VoIpMsApi{
+		ApiUsername: stubUsername,
+		ApiPassword: stubPassword,
+		ApiUrl:      server.URL,
+	}
+
+	return s, vms
+}
+
+func (s *stub) failNext(status string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.responses = append(s.responses, stubResponse{status: status})
+}
+
+func (s *stub) failNextHTTP(code int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.responses = append(s.responses, stubResponse{code: code})
+}
+
+// requests returns the API methods called so far.
+func (s *stub) requests() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.methods...)
+}
+
+func (s *stub) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	s.methods = append(s.methods, r.FormValue("method"))
+	response := stubResponse{status: "success"}
+	if len(s.responses) > 0 {
+		response, s.responses = s.responses[0], s.responses[1:]
+	}
+	s.mutex.Unlock()
+
+	if r.FormValue("api_username") != stubUsername || r.FormValue("api_password") != stubPassword {
+		response = stubResponse{status: "invalid_credentials"}
+	}
+
+	if response.code != 0 {
+		http.Error(w, http.StatusText(response.code), response.code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": response.status})
+}
@@ -0,0 +1,385 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	url2 "net/url"
+	"reflect"
+	"strings"
+)
+
+type AuthType int64
+
+const (
+	AuthTypeUserPassword AuthType = 1
+	AuthTypeStaticIP     AuthType = 2
+)
+
+func (t AuthType) String() string {
+	switch t {
+	case AuthTypeUserPassword:
+		return "user/password"
+	case AuthTypeStaticIP:
+		return "static ip"
+	default:
+		return fmt.Sprintf("auth type %d", int64(t))
+	}
+}
+
+func (t AuthType) MarshalJSON() ([]byte, error) {
+	return VoIpMsStringInt(t).MarshalJSON()
+}
+
+func (t *AuthType) UnmarshalJSON(data []byte) error {
+	return (*VoIpMsStringInt)(t).UnmarshalJSON(data)
+}
+
+type DeviceType int64
+
+const (
+	DeviceTypePBX   DeviceType = 1
+	DeviceTypePhone DeviceType = 2
+)
+
+func (t DeviceType) String() string {
+	switch t {
+	case DeviceTypePBX:
+		return "ip pbx"
+	case DeviceTypePhone:
+		return "ata/ip phone"
+	default:
+		return fmt.Sprintf("device type %d", int64(t))
+	}
+}
+
+func (t DeviceType) MarshalJSON() ([]byte, error) {
+	return VoIpMsStringInt(t).MarshalJSON()
+}
+
+func (t *DeviceType) UnmarshalJSON(data []byte) error {
+	return (*VoIpMsStringInt)(t).UnmarshalJSON(data)
+}
+
+type DTMFMode string
+
+const (
+	DTMFModeAuto    DTMFMode = "auto"
+	DTMFModeRFC2833 DTMFMode = "rfc2833"
+	DTMFModeInband  DTMFMode = "inband"
+	DTMFModeInfo    DTMFMode = "info"
+)
+
+type NATMode string
+
+const (
+	NATModeYes   NATMode = "yes"
+	NATModeNo    NATMode = "no"
+	NATModeRoute NATMode = "route"
+	NATModeNever NATMode = "never"
+)
+
+// CodecList is sent and received by VoIP.ms as a semicolon separated string.
+type CodecList []string
+
+func (c CodecList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.Join(c, ";"))
+}
+
+func (c *CodecList) UnmarshalJSON(data []byte) error {
+	var codecs string
+
+	if err := json.Unmarshal(data, &codecs); err != nil {
+		return err
+	}
+
+	*c = nil
+	for _, codec := range strings.Split(codecs, ";") {
+		if codec = strings.TrimSpace(codec); codec != "" {
+			*c = append(*c, codec)
+		}
+	}
+
+	return nil
+}
+
+// SubAccount is both returned by getSubAccounts and sent to
+// createSubAccount and setSubAccount.
+type SubAccount struct {
+	ID                   VoIpMsStringInt `json:"id" url:"id,omitempty"`
+	Account              string          `json:"account" url:"-"`
+	Username             string          `json:"username" url:"username,omitempty"`
+	Protocol             VoIpMsStringInt `json:"protocol" url:"protocol,omitempty"`
+	Description          string          `json:"description" url:"description"`
+	AuthType             AuthType        `json:"auth_type" url:"auth_type"`
+	Password             string          `json:"password" url:"password,omitempty"`
+	IP                   string          `json:"ip" url:"ip,omitempty"`
+	DeviceType           DeviceType      `json:"device_type" url:"device_type"`
+	CallerIDNumber       string          `json:"callerid_number" url:"callerid_number"`
+	CanadaRouting        VoIpMsStringInt `json:"canada_routing" url:"canada_routing"`
+	LockInternational    VoIpMsStringInt `json:"lock_international" url:"lock_international"`
+	InternationalRoute   VoIpMsStringInt `json:"international_route" url:"international_route"`
+	MusicOnHold          string          `json:"music_on_hold" url:"music_on_hold"`
+	Language             string          `json:"language" url:"language"`
+	RecordCalls          VoIpMsStringInt `json:"record_calls" url:"record_calls"`
+	AllowedCodecs        CodecList       `json:"allowed_codecs" url:"allowed_codecs,semicolon"`
+	DTMFMode             DTMFMode        `json:"dtmf_mode" url:"dtmf_mode"`
+	NAT                  NATMode         `json:"nat" url:"nat"`
+	SIPTraffic           VoIpMsStringInt `json:"sip_traffic" url:"sip_traffic,omitempty"`
+	MaxExpiry            VoIpMsStringInt `json:"max_expiry" url:"max_expiry,omitempty"`
+	RTPTimeout           VoIpMsStringInt `json:"rtp_timeout" url:"rtp_timeout,omitempty"`
+	RTPHoldTimeout       VoIpMsStringInt `json:"rtp_hold_timeout" url:"rtp_hold_timeout,omitempty"`
+	IPRestriction        string          `json:"ip_restriction" url:"ip_restriction,omitempty"`
+	EnableIPRestriction  VoIpMsStringInt `json:"enable_ip_restriction" url:"enable_ip_restriction"`
+	PopRestriction       string          `json:"pop_restriction" url:"pop_restriction,omitempty"`
+	EnablePopRestriction VoIpMsStringInt `json:"enable_pop_restriction" url:"enable_pop_restriction"`
+	SendBye              VoIpMsStringInt `json:"send_bye" url:"send_bye,omitempty"`
+	InternalExtension    string          `json:"internal_extension" url:"internal_extension,omitempty"`
+	InternalVoicemail    string          `json:"internal_voicemail" url:"internal_voicemail,omitempty"`
+	InternalDialtime     VoIpMsStringInt `json:"internal_dialtime" url:"internal_dialtime,omitempty"`
+	ResellerClient       string          `json:"reseller_client" url:"reseller_client,omitempty"`
+	ResellerPackage      string          `json:"reseller_package" url:"reseller_package,omitempty"`
+	ResellerNextBilling  VoIpMsDate      `json:"reseller_nextbilling" url:"-"`
+	Transcribe           VoIpMsStringInt `json:"transcribe" url:"transcribe,omitempty"`
+	TranscriptionLocale  string          `json:"transcription_locale" url:"transcription_locale,omitempty"`
+	TranscriptionEmail   string          `json:"transcription_email" url:"transcription_email,omitempty"`
+}
+
+func (a *SubAccount) Validate() error {
+	switch a.AuthType {
+	case AuthTypeUserPassword:
+		if a.Password == "" && a.ID == 0 {
+			return &ValidationError{Field: "password", Reason: "is required with user/password authentication"}
+		}
+	case AuthTypeStaticIP:
+		if a.IP == "" {
+			return &ValidationError{Field: "ip", Reason: "is required with static ip authentication"}
+		}
+	default:
+		return &ValidationError{Field: "auth_type", Reason: fmt.Sprintf("unknown value %d", int64(a.AuthType))}
+	}
+
+	switch a.DeviceType {
+	case DeviceTypePBX, DeviceTypePhone:
+	default:
+		return &ValidationError{Field: "device_type", Reason: fmt.Sprintf("unknown value %d", int64(a.DeviceType))}
+	}
+
+	switch a.DTMFMode {
+	case DTMFModeAuto, DTMFModeRFC2833, DTMFModeInband, DTMFModeInfo:
+	default:
+		return &ValidationError{Field: "dtmf_mode", Reason: fmt.Sprintf("unknown value %q", a.DTMFMode)}
+	}
+
+	switch a.NAT {
+	case NATModeYes, NATModeNo, NATModeRoute, NATModeNever:
+	default:
+		return &ValidationError{Field: "nat", Reason: fmt.Sprintf("unknown value %q", a.NAT)}
+	}
+
+	for _, codec := range a.AllowedCodecs {
+		if strings.ContainsAny(codec, "; ") {
+			return &ValidationError{Field: "allowed_codecs", Reason: fmt.Sprintf("invalid codec %q", codec)}
+		}
+	}
+
+	return nil
+}
+
+type GetSubAccountsRequest struct {
+	BaseRequest
+	Account string `url:"account,omitempty"`
+}
+
+func (r *GetSubAccountsRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type SubAccountRequest struct {
+	BaseRequest
+	SubAccount
+}
+
+func (r *SubAccountRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type DelSubAccountRequest struct {
+	BaseRequest
+	ID VoIpMsStringInt `url:"id"`
+}
+
+func (r *DelSubAccountRequest) ToURLValues() *url2.Values {
+	values := url2.Values{}
+	values = toURLValues(reflect.ValueOf(r))
+	return &values
+}
+
+type GetSubAccountsResponse struct {
+	BaseResponse
+	Accounts []SubAccount `json:"accounts"`
+}
+
+type CreateSubAccountResponse struct {
+	BaseResponse
+	ID      VoIpMsStringInt `json:"id"`
+	Account string          `json:"account"`
+}
+
+// ParseGetSubAccounts decodes a getSubAccounts response with its dates read
+// as UTC, unlike GetSubAccounts which uses Location.
+func ParseGetSubAccounts(data *[]byte) (*GetSubAccountsResponse, error) {
+	response := &GetSubAccountsResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func ParseCreateSubAccount(data *[]byte) (*CreateSubAccountResponse, error) {
+	response := &CreateSubAccountResponse{}
+	if err := json.Unmarshal(*data, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (vms *VoIpMsApi) GetSubAccounts(account string) (*GetSubAccountsResponse, error) {
+	return vms.GetSubAccountsContext(context.Background(), account)
+}
+
+func (vms *VoIpMsApi) GetSubAccountsContext(ctx context.Context, account string) (*GetSubAccountsResponse, error) {
+	var (
+		err      error
+		data     *[]byte
+		response *GetSubAccountsResponse
+	)
+
+	data, err = vms.NewHttpRequestContext(ctx, http.MethodGet, "getSubAccounts", &GetSubAccountsRequest{
+		Account: account,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response, err = ParseGetSubAccounts(data); err != nil {
+		return nil, err
+	}
+
+	vms.localize(response)
+	return response, nil
+}
+
+func (vms *VoIpMsApi) GetSubAccount(account string) (*SubAccount, error) {
+	return vms.GetSubAccountContext(context.Background(), account)
+}
+
+func (vms *VoIpMsApi) GetSubAccountContext(ctx context.Context, account string) (*SubAccount, error) {
+	var (
+		err      error
+		accounts *GetSubAccountsResponse
+	)
+
+	if accounts, err = vms.GetSubAccountsContext(ctx, account); err != nil {
+		return nil, err
+	}
+
+	for i := range accounts.Accounts {
+		if accounts.Accounts[i].Account == account {
+			return &accounts.Accounts[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("couldn't find sub-account %s", account)
+}
+
+// CreateSubAccount creates account.Username under the main account; the full
+// account name and ID are returned.
+func (vms *VoIpMsApi) CreateSubAccount(account SubAccount) (*CreateSubAccountResponse, error) {
+	return vms.CreateSubAccountContext(context.Background(), account)
+}
+
+func (vms *VoIpMsApi) CreateSubAccountContext(ctx context.Context, account SubAccount) (*CreateSubAccountResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	account.ID = 0
+	if account.Protocol == 0 {
+		account.Protocol = 1
+	}
+
+	if err = requireFields("username", account.Username); err != nil {
+		return nil, err
+	}
+
+	if strings.Trim(strings.ToLower(account.Username), "abcdefghijklmnopqrstuvwxyz0123456789_") != "" {
+		return nil, &ValidationError{Field: "username", Reason: "must only contain letters, digits and underscores"}
+	}
+
+	if err = account.Validate(); err != nil {
+		return nil, err
+	}
+
+	if data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "createSubAccount", &SubAccountRequest{SubAccount: account}); err != nil {
+		return nil, err
+	}
+
+	return ParseCreateSubAccount(data)
+}
+
+// SetSubAccount replaces every setting of the sub-account with account.ID.
+// Start from a value returned by GetSubAccount to only change some fields.
+func (vms *VoIpMsApi) SetSubAccount(account SubAccount) (*BaseResponse, error) {
+	return vms.SetSubAccountContext(context.Background(), account)
+}
+
+func (vms *VoIpMsApi) SetSubAccountContext(ctx context.Context, account SubAccount) (*BaseResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if account.ID == 0 {
+		return nil, &ValidationError{Field: "id", Reason: "is required"}
+	}
+	account.Username = ""
+
+	if err = account.Validate(); err != nil {
+		return nil, err
+	}
+
+	if data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "setSubAccount", &SubAccountRequest{SubAccount: account}); err != nil {
+		return nil, err
+	}
+
+	return ParseBaseResponse(data)
+}
+
+func (vms *VoIpMsApi) DelSubAccount(id VoIpMsStringInt) (*BaseResponse, error) {
+	return vms.DelSubAccountContext(context.Background(), id)
+}
+
+func (vms *VoIpMsApi) DelSubAccountContext(ctx context.Context, id VoIpMsStringInt) (*BaseResponse, error) {
+	var (
+		err  error
+		data *[]byte
+	)
+
+	if id == 0 {
+		return nil, &ValidationError{Field: "id", Reason: "is required"}
+	}
+
+	if data, err = vms.NewHttpRequestContext(ctx, http.MethodPost, "delSubAccount", &DelSubAccountRequest{ID: id}); err != nil {
+		return nil, err
+	}
+
+	return ParseBaseResponse(data)
+}
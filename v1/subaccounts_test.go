@@ -0,0 +1,107 @@
+package v1_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func newSubAccount() v1.SubAccount {
+	return v1.SubAccount{
+		Username:          "desk",
+		Description:       "Front desk",
+		AuthType:          v1.AuthTypeUserPassword,
+		Password:          "Sup3rS3cret",
+		DeviceType:        v1.DeviceTypePhone,
+		CallerIDNumber:    "5145550100",
+		LockInternational: 1,
+		AllowedCodecs:     v1.CodecList{"ulaw", "g729"},
+		DTMFMode:          v1.DTMFModeRFC2833,
+		NAT:               v1.NATModeYes,
+		InternalExtension: "101",
+	}
+}
+
+func TestSubAccountLifecycle(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	created, err := vms.CreateSubAccount(newSubAccount())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created.Account != "100000_desk" || created.ID == 0 {
+		t.Fatalf("unexpected response %+v", created)
+	}
+
+	last := fake.Requests()[len(fake.Requests())-1]
+	if last.Get("allowed_codecs") != "ulaw;g729" || last.Get("auth_type") != "1" || last.Get("nat") != "yes" {
+		t.Fatalf("unexpected parameters %v", last)
+	}
+
+	account, err := vms.GetSubAccount("100000_desk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(account.AllowedCodecs, v1.CodecList{"ulaw", "g729"}) || account.DeviceType != v1.DeviceTypePhone {
+		t.Fatalf("unexpected sub-account %+v", account)
+	}
+
+	account.Description = "Reception"
+	account.AllowedCodecs = v1.CodecList{"g722"}
+	if _, err = vms.SetSubAccount(*account); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, _ := fake.SubAccount("100000_desk")
+	if updated.Description != "Reception" || len(updated.AllowedCodecs) != 1 || updated.InternalExtension != "101" {
+		t.Fatalf("sub-account not updated %+v", updated)
+	}
+
+	if _, err = vms.GetRegistrationStatus("100000_desk"); err != nil {
+		t.Fatalf("new sub-account should be known: %v", err)
+	}
+
+	if _, err = vms.DelSubAccount(account.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = vms.GetSubAccount("100000_desk"); !errors.Is(err, v1.ErrInvalidAccount) {
+		t.Fatalf("expected ErrInvalidAccount after deletion, got %v", err)
+	}
+}
+
+func TestSubAccountValidation(t *testing.T) {
+	fake := newFake(t)
+	vms := fake.Client()
+
+	tests := map[string]func(*v1.SubAccount){
+		"username":  func(a *v1.SubAccount) { a.Username = "front desk" },
+		"password":  func(a *v1.SubAccount) { a.Password = "" },
+		"ip":        func(a *v1.SubAccount) { a.AuthType = v1.AuthTypeStaticIP },
+		"dtmf_mode": func(a *v1.SubAccount) { a.DTMFMode = "pulse" },
+		"nat":       func(a *v1.SubAccount) { a.NAT = "maybe" },
+	}
+
+	for field, mutate := range tests {
+		account := newSubAccount()
+		mutate(&account)
+
+		var validationError *v1.ValidationError
+		if _, err := vms.CreateSubAccount(account); !errors.As(err, &validationError) || validationError.Field != field {
+			t.Errorf("%s: expected ValidationError, got %v", field, err)
+		}
+	}
+
+	if _, err := vms.SetSubAccount(newSubAccount()); err == nil {
+		t.Error("SetSubAccount without ID should fail")
+	}
+
+	if len(fake.Requests()) != 0 {
+		t.Fatal("invalid requests must not reach the API")
+	}
+}
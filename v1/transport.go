@@ -0,0 +1,138 @@
+package v1
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	url2 "net/url"
+	"time"
+)
+
+// Middleware wraps the transport used for every API call. Middlewares are
+// applied in order, the first one being the outermost.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+type RoundTripperFunc func(request *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func (vms *VoIpMsApi) Use(middlewares ...Middleware) {
+	vms.Middlewares = append(vms.Middlewares, middlewares...)
+}
+
+func chainMiddlewares(transport http.RoundTripper, middlewares []Middleware) http.RoundTripper {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		transport = middlewares[i](transport)
+	}
+	return transport
+}
+
+func (vms *VoIpMsApi) httpClient() *http.Client {
+	var client http.Client
+
+	if vms.HttpClient != nil {
+		client = *vms.HttpClient
+	}
+	if client.Timeout == 0 {
+		client.Timeout = vms.ApiTimeout
+	}
+
+	if len(vms.Middlewares) == 0 {
+		return &client
+	}
+
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	client.Transport = chainMiddlewares(transport, vms.Middlewares)
+
+	return &client
+}
+
+func WithHeader(key string, value string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			request = request.Clone(request.Context())
+			request.Header.Set(key, value)
+			return next.RoundTrip(request)
+		})
+	}
+}
+
+func WithUserAgent(userAgent string) Middleware {
+	return WithHeader("User-Agent", userAgent)
+}
+
+func WithLogger(logger *log.Logger) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			start := time.Now()
+			response, err := next.RoundTrip(request)
+			method := requestAPIMethod(request)
+			if err != nil {
+				logger.Printf("%s %s failed after %v: %s", request.Method, method, time.Since(start), RedactParams(err.Error()))
+			} else {
+				logger.Printf("%s %s %d in %v", request.Method, method, response.StatusCode, time.Since(start))
+			}
+			return response, err
+		})
+	}
+}
+
+// WithDump writes every request and response to w, with credentials masked.
+func WithDump(w io.Writer) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			if dump, err := httputil.DumpRequestOut(request, true); err == nil {
+				_, _ = fmt.Fprintf(w, "%s\n", RedactParams(string(dump)))
+			}
+
+			response, err := next.RoundTrip(request)
+			if err != nil {
+				_, _ = fmt.Fprintf(w, "error: %s\n", RedactParams(err.Error()))
+				return response, err
+			}
+
+			if dump, err := httputil.DumpResponse(response, true); err == nil {
+				_, _ = fmt.Fprintf(w, "%s\n", RedactParams(string(dump)))
+			}
+
+			return response, nil
+		})
+	}
+}
+
+// requestAPIMethod finds the VoIP.ms method of a request without consuming
+// its body.
+func requestAPIMethod(request *http.Request) string {
+	if method := request.URL.Query().Get("method"); method != "" {
+		return method
+	}
+
+	if request.GetBody == nil {
+		return ""
+	}
+
+	body, err := request.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return ""
+	}
+
+	values, err := url2.ParseQuery(string(data))
+	if err != nil {
+		return ""
+	}
+
+	return values.Get("method")
+}
@@ -0,0 +1,61 @@
+package v1_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	var order []string
+
+	_, vms := newStub(t)
+
+	record := func(name string) v1.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return v1.RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(request)
+			})
+		}
+	}
+
+	vms.Use(record("outer"), record("inner"), v1.WithUserAgent("voipms-test"))
+
+	if _, err := vms.GetServersInfo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(order, ",") != "outer,inner" {
+		t.Fatalf("unexpected middleware order %v", order)
+	}
+}
+
+func TestApiTimeoutWithHttpClient(t *testing.T) {
+	vms := v1.NewVoIpMsClient(stubUsername, stubPassword)
+	vms.ApiTimeout = 20 * time.Millisecond
+	vms.HttpClient = &http.Client{
+		Transport: v1.RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			<-request.Context().Done()
+			return nil, request.Context().Err()
+		}),
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := vms.GetServersInfo()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected a timeout")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ApiTimeout ignored when HttpClient is set")
+	}
+}
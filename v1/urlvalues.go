@@ -0,0 +1,205 @@
+package v1
+
+import (
+	"encoding"
+	"fmt"
+	url2 "net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// urlTag is a parsed `url:"name,option,..."` struct tag. Supported options:
+//
+//	omitempty  skip the parameter when the field holds its zero value
+//	yesno      send booleans as yes/no instead of 1/0
+//	date       send time.Time as a VoIP.ms date instead of a date and time
+//	semicolon  join slices with ";" instead of repeating the parameter
+//
+// An empty name uses the field name, and "-" skips the field.
+type urlTag struct {
+	name      string
+	omitEmpty bool
+	yesNo     bool
+	date      bool
+	semicolon bool
+}
+
+func parseURLTag(field reflect.StructField) (urlTag, bool) {
+	tagValue, ok := field.Tag.Lookup("url")
+	if !ok || tagValue == "-" {
+		return urlTag{}, false
+	}
+
+	parts := strings.Split(tagValue, ",")
+	tag := urlTag{name: parts[0]}
+	if tag.name == "" {
+		tag.name = field.Name
+	}
+
+	for _, option := range parts[1:] {
+		switch option {
+		case "omitempty":
+			tag.omitEmpty = true
+		case "yesno":
+			tag.yesNo = true
+		case "date":
+			tag.date = true
+		case "semicolon":
+			tag.semicolon = true
+		}
+	}
+
+	return tag, true
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func isEmptyURLValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+func formatURLValue(v reflect.Value, tag urlTag, location *time.Location) string {
+	// A VoIpMsDate is a calendar day, sent as is by its MarshalText.
+	switch v.Type() {
+	case timeType:
+		return formatURLTime(v.Interface().(time.Time), tag.date, location)
+	case dateTimeType:
+		if date := v.Interface().(VoIpMsDateTime); !date.IsZero() {
+			return formatURLTime(date.Time, false, location)
+		}
+	}
+
+	// Booleans keep the 1/0 or yes/no encoding VoIP.ms expects in parameters.
+	if v.Kind() != reflect.Bool && v.Type().Implements(textMarshalerType) {
+		if text, err := v.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		switch {
+		case tag.yesNo && v.Bool():
+			return "yes"
+		case tag.yesNo:
+			return "no"
+		case v.Bool():
+			return "1"
+		default:
+			return "0"
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	case reflect.String:
+		return v.String()
+	}
+
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+func formatURLTime(t time.Time, dateOnly bool, location *time.Location) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	if dateOnly {
+		return t.In(location).Format(voipmsDateFormat)
+	}
+	return t.In(location).Format(voipmsDateTimeFormat)
+}
+
+// addURLValue adds the parameter for a field. A nil pointer is never sent
+// while a non-nil one is always sent, even if it points to a zero value, so
+// pointers can be used for optional fields that may need to be cleared.
+func addURLValue(values url2.Values, tag urlTag, v reflect.Value, location *time.Location) {
+	isPointer := false
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		isPointer = true
+		v = v.Elem()
+	}
+
+	if tag.omitEmpty && !isPointer && isEmptyURLValue(v) {
+		return
+	}
+
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 {
+		var items []string
+		for i := 0; i < v.Len(); i++ {
+			item := v.Index(i)
+			if item.Kind() == reflect.Ptr && item.IsNil() {
+				continue
+			}
+			items = append(items, formatURLValue(reflect.Indirect(item), tag, location))
+		}
+
+		if tag.semicolon {
+			values.Add(tag.name, strings.Join(items, ";"))
+		} else {
+			for _, item := range items {
+				values.Add(tag.name, item)
+			}
+		}
+		return
+	}
+
+	values.Add(tag.name, formatURLValue(v, tag, location))
+}
+
+// toURLValues encodes the fields of a request, sending times in the time zone
+// set on its BaseRequest by the client, UTC otherwise.
+func toURLValues(v reflect.Value) url2.Values {
+	location := time.UTC
+	if request, ok := v.Interface().(interface{ urlLocation() *time.Location }); ok && request.urlLocation() != nil {
+		location = request.urlLocation()
+	}
+
+	return structURLValues(v, location)
+}
+
+func structURLValues(v reflect.Value, location *time.Location) url2.Values {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return url2.Values{}
+		}
+		v = v.Elem()
+	}
+
+	values := url2.Values{}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		if tag, ok := parseURLTag(field); ok {
+			addURLValue(values, tag, v.Field(i), location)
+		} else if field.Anonymous {
+			embeddedValues := structURLValues(v.Field(i), location)
+			for k, v := range embeddedValues {
+				values[k] = v
+			}
+		}
+	}
+
+	return values
+}
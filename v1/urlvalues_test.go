@@ -0,0 +1,184 @@
+package v1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func encode(t *testing.T, request interface{}) string {
+	t.Helper()
+	return toURLValues(reflect.ValueOf(request)).Encode()
+}
+
+func TestURLValuesTagName(t *testing.T) {
+	request := struct {
+		Did      string `url:"did"`
+		Renamed  string `url:",omitempty"`
+		Skipped  string `url:"-"`
+		Untagged string
+	}{Did: "5145550100", Renamed: "x", Skipped: "y", Untagged: "z"}
+
+	if got := encode(t, request); got != "Renamed=x&did=5145550100" {
+		t.Fatalf("unexpected encoding %s", got)
+	}
+}
+
+func TestURLValuesOmitEmpty(t *testing.T) {
+	request := struct {
+		Client string   `url:"client,omitempty"`
+		Did    string   `url:"did,omitempty"`
+		Pop    int      `url:"pop,omitempty"`
+		Codecs []string `url:"codecs,omitempty"`
+		Note   string   `url:"note"`
+	}{Did: "5145550100"}
+
+	if got := encode(t, request); got != "did=5145550100&note=" {
+		t.Fatalf("unexpected encoding %s", got)
+	}
+}
+
+func TestURLValuesEmbedded(t *testing.T) {
+	request := &GetDidInfoRequest{Did: "5145550100"}
+	request.SetApiMethod("getDIDsInfo")
+	request.SetApiUser("user")
+	request.SetApiPassword("secret")
+
+	expected := "api_password=secret&api_username=user&did=5145550100&method=getDIDsInfo"
+	if got := request.ToURLValues().Encode(); got != expected {
+		t.Fatalf("unexpected encoding %s", got)
+	}
+}
+
+func TestURLValuesBool(t *testing.T) {
+	request := struct {
+		Enabled  bool             `url:"enabled"`
+		Disabled bool             `url:"disabled"`
+		Yes      bool             `url:"yes,yesno"`
+		No       bool             `url:"no,yesno"`
+		Omitted  bool             `url:"omitted,omitempty"`
+		Custom   VoIpMsStringBool `url:"custom"`
+	}{Enabled: true, Yes: true, Custom: true}
+
+	if got := encode(t, request); got != "custom=1&disabled=0&enabled=1&no=no&yes=yes" {
+		t.Fatalf("unexpected encoding %s", got)
+	}
+}
+
+func TestURLValuesNumbers(t *testing.T) {
+	request := struct {
+		Int      int               `url:"int"`
+		Int64    VoIpMsStringInt   `url:"int64"`
+		Uint     uint8             `url:"uint"`
+		Float    float64           `url:"float"`
+		Custom   VoIpMsStringFloat `url:"custom"`
+		Negative int               `url:"negative"`
+	}{Int: 8, Int64: 29, Uint: 3, Float: 0.01, Custom: 2.5, Negative: -1}
+
+	if got := encode(t, request); got != "custom=2.5&float=0.01&int=8&int64=29&negative=-1&uint=3" {
+		t.Fatalf("unexpected encoding %s", got)
+	}
+}
+
+func TestURLValuesTime(t *testing.T) {
+	moment := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	request := struct {
+		DateTime    time.Time      `url:"datetime"`
+		Date        time.Time      `url:"date,date"`
+		VoIpMsDate  VoIpMsDate     `url:"vms_date"`
+		VoIpMsTime  VoIpMsDateTime `url:"vms_datetime"`
+		Zero        time.Time      `url:"zero"`
+		ZeroDate    VoIpMsDate     `url:"zero_date"`
+		ZeroOmitted VoIpMsDate     `url:"zero_omitted,omitempty"`
+	}{
+		DateTime:   moment,
+		Date:       moment,
+		VoIpMsDate: VoIpMsDate{moment},
+		VoIpMsTime: VoIpMsDateTime{moment},
+	}
+
+	values := toURLValues(reflect.ValueOf(request))
+
+	expected := map[string]string{
+		"datetime":     "2023-04-05 06:07:08",
+		"date":         "2023-04-05",
+		"vms_date":     "2023-04-05",
+		"vms_datetime": "2023-04-05 06:07:08",
+		"zero":         "",
+		"zero_date":    "0000-00-00",
+	}
+
+	for key, value := range expected {
+		if values.Get(key) != value {
+			t.Errorf("%s: expected %q, got %q", key, value, values.Get(key))
+		}
+	}
+
+	if _, ok := values["zero_omitted"]; ok {
+		t.Errorf("zero_omitted should have been omitted")
+	}
+}
+
+func TestURLValuesTimeLocation(t *testing.T) {
+	moment := time.Date(2023, 4, 5, 2, 7, 8, 0, time.UTC)
+	request := &struct {
+		BaseRequest
+		DateTime   time.Time      `url:"datetime"`
+		VoIpMsTime VoIpMsDateTime `url:"vms_datetime"`
+		VoIpMsDate VoIpMsDate     `url:"vms_date"`
+	}{
+		DateTime:   moment,
+		VoIpMsTime: VoIpMsDateTime{moment},
+		VoIpMsDate: VoIpMsDate{moment},
+	}
+	request.setLocation(time.FixedZone("EST", -5*60*60))
+
+	values := toURLValues(reflect.ValueOf(request))
+
+	expected := map[string]string{
+		"datetime":     "2023-04-04 21:07:08",
+		"vms_datetime": "2023-04-04 21:07:08",
+		"vms_date":     "2023-04-05",
+	}
+
+	for key, value := range expected {
+		if values.Get(key) != value {
+			t.Errorf("%s: expected %q, got %q", key, value, values.Get(key))
+		}
+	}
+}
+
+func TestURLValuesSlices(t *testing.T) {
+	request := struct {
+		Repeated  []string `url:"codec"`
+		Joined    []string `url:"codecs,semicolon"`
+		Numbers   []int    `url:"pops,semicolon"`
+		EmptyJoin []string `url:"empty,semicolon,omitempty"`
+	}{
+		Repeated: []string{"ulaw", "g729"},
+		Joined:   []string{"ulaw", "g729"},
+		Numbers:  []int{8, 29},
+	}
+
+	expected := "codec=ulaw&codec=g729&codecs=ulaw%3Bg729&pops=8%3B29"
+	if got := encode(t, request); got != expected {
+		t.Fatalf("unexpected encoding %s", got)
+	}
+}
+
+func TestURLValuesPointers(t *testing.T) {
+	pop := 0
+	enabled := false
+	note := ""
+
+	request := struct {
+		Pop     *int    `url:"pop"`
+		Enabled *bool   `url:"enabled"`
+		Note    *string `url:"note,omitempty"`
+		Unset   *string `url:"unset"`
+	}{Pop: &pop, Enabled: &enabled, Note: &note}
+
+	if got := encode(t, request); got != "enabled=0&note=&pop=0" {
+		t.Fatalf("unexpected encoding %s", got)
+	}
+}
@@ -0,0 +1,347 @@
+// Package voipmshook receives the SMS and MMS URL callbacks VoIP.ms sends
+// when a DID gets a message.
+//
+// Point the DID callback at the handler with every variable VoIP.ms offers:
+//
+//	https://example.com/sms?id={ID}&date={TIMESTAMP}&from={FROM}&to={TO}&message={MESSAGE}&media={MEDIA}
+//
+// The JSON body VoIP.ms posts to webhooks is understood as well.
+//
+//	hook := voipmshook.NewHandler(func(ctx context.Context, event *voipmshook.Event) error {
+//		log.Printf("%s from %s: %s", event.Type, event.From, event.Message)
+//		return nil
+//	})
+//	hook.Location = vms.Location
+//	http.Handle("/sms", hook)
+package voipmshook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	url2 "net/url"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+// okBody is the answer VoIP.ms waits for before it stops retrying a callback.
+const okBody = "ok"
+
+// DefaultDedupSize is the number of message IDs remembered to drop retried
+// deliveries.
+const DefaultDedupSize = 1024
+
+type EventType string
+
+const (
+	EventSMS EventType = "SMS"
+	EventMMS EventType = "MMS"
+)
+
+// Event is a message received by a DID.
+type Event struct {
+	ID      string    `json:"id"`
+	Type    EventType `json:"type"`
+	Date    time.Time `json:"date"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Message string    `json:"message"`
+	Media   []string  `json:"media,omitempty"`
+}
+
+// HandlerFunc processes an event. Returning an error answers VoIP.ms with a
+// server error so the delivery is retried.
+type HandlerFunc func(ctx context.Context, event *Event) error
+
+// Handler is an http.Handler for VoIP.ms message callbacks. Handlers are
+// called in order and a delivery is acknowledged once all of them succeed.
+type Handler struct {
+	// DedupSize bounds the number of IDs remembered, DefaultDedupSize when
+	// zero.
+	DedupSize int
+	// ErrorLog receives handler and parsing errors, the standard logger
+	// when nil.
+	ErrorLog *log.Logger
+	// Location is the time zone of the account, the date of a callback
+	// being sent in it. UTC when nil.
+	Location *time.Location
+
+	handlers []HandlerFunc
+
+	mutex    sync.Mutex
+	seen     map[string]bool
+	order    []string
+	inFlight map[string]bool
+}
+
+func NewHandler(handlers ...HandlerFunc) *Handler {
+	return &Handler{handlers: handlers}
+}
+
+// Handle adds a handler called for every new event.
+func (h *Handler) Handle(handler HandlerFunc) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.handlers = append(h.handlers, handler)
+}
+
+func (h *Handler) logf(format string, args ...interface{}) {
+	if h.ErrorLog != nil {
+		h.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	event, err := parseRequest(r, h.Location)
+	if err != nil {
+		h.logf("voipmshook: invalid callback: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	handlers, state := h.claim(event.ID)
+	switch state {
+	case claimSeen:
+		_, _ = io.WriteString(w, okBody)
+		return
+	case claimInFlight:
+		http.Error(w, "delivery in progress", http.StatusServiceUnavailable)
+		return
+	}
+
+	for _, handler := range handlers {
+		if err = handler(r.Context(), event); err != nil {
+			h.release(event.ID, false)
+			h.logf("voipmshook: message %s: %v", event.ID, err)
+			http.Error(w, "handler failed", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	h.release(event.ID, true)
+	_, _ = io.WriteString(w, okBody)
+}
+
+type claimState int
+
+const (
+	claimNew claimState = iota
+	claimSeen
+	claimInFlight
+)
+
+// claim marks id as being processed. Events without an ID cannot be
+// deduplicated and are always processed.
+func (h *Handler) claim(id string) ([]HandlerFunc, claimState) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	handlers := append([]HandlerFunc(nil), h.handlers...)
+
+	if id == "" {
+		return handlers, claimNew
+	}
+
+	if h.seen[id] {
+		return nil, claimSeen
+	}
+
+	if h.inFlight[id] {
+		return nil, claimInFlight
+	}
+
+	if h.inFlight == nil {
+		h.inFlight = map[string]bool{}
+	}
+	h.inFlight[id] = true
+
+	return handlers, claimNew
+}
+
+func (h *Handler) release(id string, delivered bool) {
+	if id == "" {
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.inFlight, id)
+
+	if !delivered {
+		return
+	}
+
+	if h.seen == nil {
+		h.seen = map[string]bool{}
+	}
+
+	size := h.DedupSize
+	if size <= 0 {
+		size = DefaultDedupSize
+	}
+
+	h.seen[id] = true
+	h.order = append(h.order, id)
+	for len(h.order) > size {
+		delete(h.seen, h.order[0])
+		h.order = h.order[1:]
+	}
+}
+
+// ParseRequest reads an event from the query string, a form or a JSON body.
+// The date of a query string or form is read as UTC.
+func ParseRequest(r *http.Request) (*Event, error) {
+	return parseRequest(r, time.UTC)
+}
+
+func parseRequest(r *http.Request, location *time.Location) (*Event, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if r.Method == http.MethodPost && mediaType == "application/json" {
+		return parseJSON(r.Body)
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	return parseValues(r.Form, location)
+}
+
+// ParseValues builds an event from callback parameters named after the
+// VoIP.ms variables: id, date, from, to, message and media. The date is read
+// as UTC.
+func ParseValues(values url2.Values) (*Event, error) {
+	return parseValues(values, time.UTC)
+}
+
+func parseValues(values url2.Values, location *time.Location) (*Event, error) {
+	if location == nil {
+		location = time.UTC
+	}
+
+	event := &Event{
+		ID:      values.Get("id"),
+		From:    values.Get("from"),
+		To:      values.Get("to"),
+		Message: values.Get("message"),
+	}
+
+	if event.From == "" || event.To == "" {
+		return nil, errors.New("from and to are required")
+	}
+
+	if date := values.Get("date"); date != "" {
+		var dateTime v1.VoIpMsDateTime
+		if err := dateTime.UnmarshalText([]byte(date)); err != nil {
+			return nil, fmt.Errorf("invalid date %q: %w", date, err)
+		}
+		t := dateTime.Time
+		event.Date = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, location)
+	}
+
+	for _, media := range values["media"] {
+		event.Media = append(event.Media, splitMedia(media)...)
+	}
+
+	event.Type = EventSMS
+	if len(event.Media) > 0 {
+		event.Type = EventMMS
+	}
+
+	return event, nil
+}
+
+// splitMedia splits the media URLs VoIP.ms joins with commas. URLs may hold
+// commas themselves, so only a comma followed by another URL separates them.
+func splitMedia(media string) []string {
+	var urls []string
+
+	start := 0
+	for i := 0; i <= len(media); i++ {
+		if i < len(media) {
+			if media[i] != ',' {
+				continue
+			}
+			if next := strings.TrimSpace(media[i+1:]); !strings.HasPrefix(next, "http://") && !strings.HasPrefix(next, "https://") {
+				continue
+			}
+		}
+
+		if url := strings.TrimSpace(media[start:i]); url != "" {
+			urls = append(urls, url)
+		}
+		start = i + 1
+	}
+
+	return urls
+}
+
+type jsonPhone struct {
+	PhoneNumber string `json:"phone_number"`
+}
+
+type jsonCallback struct {
+	Data struct {
+		ID        string `json:"id"`
+		EventType string `json:"event_type"`
+		Payload   struct {
+			ID         json.Number `json:"id"`
+			From       jsonPhone   `json:"from"`
+			To         []jsonPhone `json:"to"`
+			Text       string      `json:"text"`
+			Type       string      `json:"type"`
+			ReceivedAt time.Time   `json:"received_at"`
+			Media      []struct {
+				URL string `json:"url"`
+			} `json:"media"`
+		} `json:"payload"`
+	} `json:"data"`
+}
+
+func parseJSON(body io.Reader) (*Event, error) {
+	var callback jsonCallback
+
+	if err := json.NewDecoder(body).Decode(&callback); err != nil {
+		return nil, err
+	}
+
+	payload := &callback.Data.Payload
+	if payload.From.PhoneNumber == "" || len(payload.To) == 0 {
+		return nil, errors.New("from and to are required")
+	}
+
+	event := &Event{
+		ID:      payload.ID.String(),
+		Date:    payload.ReceivedAt,
+		From:    payload.From.PhoneNumber,
+		To:      payload.To[0].PhoneNumber,
+		Message: payload.Text,
+		Type:    EventSMS,
+	}
+
+	if event.ID == "" {
+		event.ID = callback.Data.ID
+	}
+
+	for _, media := range payload.Media {
+		event.Media = append(event.Media, media.URL)
+	}
+
+	if len(event.Media) > 0 || strings.EqualFold(payload.Type, string(EventMMS)) {
+		event.Type = EventMMS
+	}
+
+	return event, nil
+}
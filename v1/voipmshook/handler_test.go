@@ -0,0 +1,200 @@
+package voipmshook_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	url2 "net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ticpu/voipms-gorest/v1/voipmshook"
+)
+
+func callback(t *testing.T, handler http.Handler, request *http.Request) (int, string) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	body, _ := io.ReadAll(recorder.Result().Body)
+	return recorder.Code, string(body)
+}
+
+func queryRequest(values url2.Values) *http.Request {
+	return httptest.NewRequest(http.MethodGet, "/sms?"+values.Encode(), nil)
+}
+
+func quietHandler(handlers ...voipmshook.HandlerFunc) *voipmshook.Handler {
+	hook := voipmshook.NewHandler(handlers...)
+	hook.ErrorLog = log.New(io.Discard, "", 0)
+	return hook
+}
+
+func TestHandlerDeduplicatesRetries(t *testing.T) {
+	var events []*voipmshook.Event
+	hook := quietHandler(func(_ context.Context, event *voipmshook.Event) error {
+		events = append(events, event)
+		return nil
+	})
+
+	values := url2.Values{
+		"id":      {"3215"},
+		"date":    {"2024-03-01 12:34:56"},
+		"from":    {"5145550101"},
+		"to":      {"5145550100"},
+		"message": {"hello"},
+	}
+
+	for i := 0; i < 2; i++ {
+		if code, body := callback(t, hook, queryRequest(values)); code != http.StatusOK || body != "ok" {
+			t.Fatalf("expected ok, got %d %q", code, body)
+		}
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected a single event, got %d", len(events))
+	}
+
+	event := events[0]
+	if event.Type != voipmshook.EventSMS || event.Message != "hello" || event.From != "5145550101" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	if !event.Date.Equal(time.Date(2024, time.March, 1, 12, 34, 56, 0, time.UTC)) {
+		t.Fatalf("unexpected date %v", event.Date)
+	}
+}
+
+func TestHandlerRetriesFailedDeliveries(t *testing.T) {
+	calls := 0
+	hook := quietHandler(func(_ context.Context, _ *voipmshook.Event) error {
+		if calls++; calls == 1 {
+			return errors.New("database down")
+		}
+		return nil
+	})
+
+	values := url2.Values{"id": {"1"}, "from": {"5145550101"}, "to": {"5145550100"}, "message": {"hi"}}
+
+	if code, _ := callback(t, hook, queryRequest(values)); code != http.StatusInternalServerError {
+		t.Fatalf("expected a server error, got %d", code)
+	}
+
+	if code, body := callback(t, hook, queryRequest(values)); code != http.StatusOK || body != "ok" {
+		t.Fatalf("expected the retry to succeed, got %d %q", code, body)
+	}
+
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestHandlerForgetsOldIDs(t *testing.T) {
+	calls := 0
+	hook := quietHandler(func(_ context.Context, _ *voipmshook.Event) error {
+		calls++
+		return nil
+	})
+	hook.DedupSize = 2
+
+	for _, id := range []string{"1", "2", "3", "1"} {
+		callback(t, hook, queryRequest(url2.Values{"id": {id}, "from": {"1"}, "to": {"2"}}))
+	}
+
+	if calls != 4 {
+		t.Fatalf("expected the first ID to be forgotten, got %d calls", calls)
+	}
+}
+
+func TestHandlerMMSForm(t *testing.T) {
+	var event *voipmshook.Event
+	hook := quietHandler(func(_ context.Context, e *voipmshook.Event) error {
+		event = e
+		return nil
+	})
+
+	form := url2.Values{"id": {"9"}, "from": {"5145550101"}, "to": {"5145550100"}, "media": {"https://a/1.png,https://a/2.png"}}
+	request := httptest.NewRequest(http.MethodPost, "/sms", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if code, _ := callback(t, hook, request); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+
+	if event.Type != voipmshook.EventMMS || len(event.Media) != 2 || event.Media[1] != "https://a/2.png" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+}
+
+func TestParseValuesMediaWithCommas(t *testing.T) {
+	signed := "https://cdn.example.com/1.png?sig=a,b&keys=c,d"
+	values := url2.Values{"from": {"5145550101"}, "to": {"5145550100"}, "media": {signed + ", https://a/2.png,http://a/3.png"}}
+
+	event, err := voipmshook.ParseValues(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{signed, "https://a/2.png", "http://a/3.png"}; !reflect.DeepEqual(event.Media, expected) {
+		t.Fatalf("expected %q, got %q", expected, event.Media)
+	}
+}
+
+func TestHandlerLocation(t *testing.T) {
+	var event *voipmshook.Event
+	hook := quietHandler(func(_ context.Context, e *voipmshook.Event) error {
+		event = e
+		return nil
+	})
+	hook.Location = time.FixedZone("EST", -5*3600)
+
+	values := url2.Values{"id": {"10"}, "date": {"2024-03-01 10:30:00"}, "from": {"5145550101"}, "to": {"5145550100"}}
+	if code, _ := callback(t, hook, queryRequest(values)); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+
+	if expected := time.Date(2024, time.March, 1, 15, 30, 0, 0, time.UTC); !event.Date.Equal(expected) {
+		t.Fatalf("expected %v, got %v", expected, event.Date)
+	}
+}
+
+func TestHandlerJSON(t *testing.T) {
+	var event *voipmshook.Event
+	hook := quietHandler(func(_ context.Context, e *voipmshook.Event) error {
+		event = e
+		return nil
+	})
+
+	body := `{"data":{"id":"abc","event_type":"message.received","payload":{"id":42,
+		"from":{"phone_number":"5145550101"},"to":[{"phone_number":"5145550100"}],
+		"text":"hello","type":"MMS","received_at":"2024-03-01T12:34:56+00:00",
+		"media":[{"url":"https://a/1.png"}]}}}`
+	request := httptest.NewRequest(http.MethodPost, "/sms", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+
+	if code, _ := callback(t, hook, request); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+
+	if event.ID != "42" || event.Type != voipmshook.EventMMS || event.To != "5145550100" || event.Message != "hello" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+}
+
+func TestHandlerRejectsInvalidCallbacks(t *testing.T) {
+	hook := quietHandler()
+
+	for _, values := range []url2.Values{
+		{"id": {"1"}, "to": {"5145550100"}},
+		{"id": {"1"}, "from": {"1"}, "to": {"2"}, "date": {"yesterday"}},
+	} {
+		if code, _ := callback(t, hook, queryRequest(values)); code != http.StatusBadRequest {
+			t.Fatalf("expected a bad request for %v, got %d", values, code)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package voipmstest
+
+import (
+	url2 "net/url"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+// SetBalance sets the balance of the main account returned by getBalance.
+func (s *Server) SetBalance(balance v1.Balance) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.balance = balance
+}
+
+func (s *Server) AddTransaction(transaction v1.Transaction) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.transactions = append(s.transactions, transaction)
+}
+
+func (s *Server) getBalance(params url2.Values) (string, map[string]interface{}) {
+	balance := v1.Balance{CurrentBalance: s.balance.CurrentBalance}
+
+	if advanced := params.Get("advanced"); advanced == "1" || advanced == "true" {
+		balance = s.balance
+	}
+
+	return "success", map[string]interface{}{"balance": balance}
+}
+
+func (s *Server) getTransactionHistory(params url2.Values) (string, map[string]interface{}) {
+	var (
+		from         v1.VoIpMsDate
+		to           v1.VoIpMsDate
+		transactions []map[string]interface{}
+	)
+
+	if params.Get("date_from") == "" || params.Get("date_to") == "" {
+		return "missing_params", nil
+	}
+
+	if from.UnmarshalText([]byte(params.Get("date_from"))) != nil || to.UnmarshalText([]byte(params.Get("date_to"))) != nil {
+		return "invalid_date", nil
+	}
+
+	if to.Before(from.Time) {
+		return "invalid_daterange", nil
+	}
+
+	end := to.Add(24 * time.Hour)
+	for _, transaction := range s.transactions {
+		if transaction.Date.Before(from.Time) || !transaction.Date.Before(end) {
+			continue
+		}
+		// VoIP.ms spells the amount "ammount" in this call.
+		transactions = append(transactions, map[string]interface{}{
+			"date":        transaction.Date,
+			"uniqueid":    transaction.UniqueID,
+			"type":        transaction.Type,
+			"description": transaction.Description,
+			"ammount":     transaction.Amount,
+		})
+	}
+
+	return "success", map[string]interface{}{"transactions": transactions}
+}
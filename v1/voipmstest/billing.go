@@ -0,0 +1,169 @@
+package voipmstest
+
+import (
+	url2 "net/url"
+	"strconv"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+type clientBilling struct {
+	charges   []v1.BillingEntry
+	deposits  []v1.BillingEntry
+	packages  []v1.ClientPackage
+	threshold v1.ClientThreshold
+	nextID    int
+}
+
+func (s *Server) clientBilling(client string) *clientBilling {
+	billing, ok := s.billing[client]
+	if !ok {
+		billing = &clientBilling{}
+		s.billing[client] = billing
+	}
+	return billing
+}
+
+func (s *Server) AddClientPackage(client string, clientPackage v1.ClientPackage) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	billing := s.clientBilling(client)
+	billing.packages = append(billing.packages, clientPackage)
+}
+
+// billingClient resolves the client parameter shared by the billing calls.
+func (s *Server) billingClient(params url2.Values) (int, string) {
+	client := params.Get("client")
+	if client == "" {
+		return -1, "missing_client"
+	}
+
+	i := s.findClient(client)
+	if i < 0 {
+		return -1, "invalid_client"
+	}
+
+	return i, ""
+}
+
+func (s *Server) getResellerBalance(params url2.Values) (string, map[string]interface{}) {
+	i, status := s.billingClient(params)
+	if i < 0 {
+		return status, nil
+	}
+
+	var spent v1.VoIpMsMoney
+	for _, charge := range s.clientBilling(s.clients[i].Client).charges {
+		spent += charge.Amount
+	}
+
+	return "success", map[string]interface{}{
+		"balance": v1.ResellerBalance{
+			CurrentBalance: s.clients[i].Balance,
+			SpentTotal:     spent,
+			TimeTotal:      "0:00:00",
+			TimeToday:      "0:00:00",
+		},
+	}
+}
+
+func (s *Server) postBillingEntry(params url2.Values, amountParam string, sign v1.VoIpMsMoney) (string, map[string]interface{}) {
+	i, status := s.billingClient(params)
+	if i < 0 {
+		return status, nil
+	}
+
+	amount, err := v1.ParseMoney(params.Get(amountParam))
+	if err != nil || amount <= 0 {
+		return "invalid_" + amountParam, nil
+	}
+
+	if params.Get("test") == "1" {
+		return "success", nil
+	}
+
+	billing := s.clientBilling(s.clients[i].Client)
+	billing.nextID++
+	entry := v1.BillingEntry{
+		ID:          strconv.Itoa(billing.nextID),
+		Date:        v1.VoIpMsDate{Time: time.Now().UTC().Truncate(24 * time.Hour)},
+		Amount:      amount,
+		Description: params.Get("description"),
+	}
+
+	if sign < 0 {
+		billing.charges = append(billing.charges, entry)
+	} else {
+		billing.deposits = append(billing.deposits, entry)
+	}
+	s.clients[i].Balance += sign * amount
+
+	return "success", nil
+}
+
+func (s *Server) addCharge(params url2.Values) (string, map[string]interface{}) {
+	return s.postBillingEntry(params, "charge", -1)
+}
+
+func (s *Server) addPayment(params url2.Values) (string, map[string]interface{}) {
+	return s.postBillingEntry(params, "payment", 1)
+}
+
+func (s *Server) getCharges(params url2.Values) (string, map[string]interface{}) {
+	i, status := s.billingClient(params)
+	if i < 0 {
+		return status, nil
+	}
+
+	return "success", map[string]interface{}{"charges": s.clientBilling(s.clients[i].Client).charges}
+}
+
+func (s *Server) getDeposits(params url2.Values) (string, map[string]interface{}) {
+	i, status := s.billingClient(params)
+	if i < 0 {
+		return status, nil
+	}
+
+	return "success", map[string]interface{}{"deposits": s.clientBilling(s.clients[i].Client).deposits}
+}
+
+func (s *Server) getClientPackages(params url2.Values) (string, map[string]interface{}) {
+	i, status := s.billingClient(params)
+	if i < 0 {
+		return status, nil
+	}
+
+	return "success", map[string]interface{}{"packages": s.clientBilling(s.clients[i].Client).packages}
+}
+
+func (s *Server) getClientThreshold(params url2.Values) (string, map[string]interface{}) {
+	i, status := s.billingClient(params)
+	if i < 0 {
+		return status, nil
+	}
+
+	return "success", map[string]interface{}{"threshold_information": s.clientBilling(s.clients[i].Client).threshold}
+}
+
+func (s *Server) setClientThreshold(params url2.Values) (string, map[string]interface{}) {
+	i, status := s.billingClient(params)
+	if i < 0 {
+		return status, nil
+	}
+
+	threshold, err := v1.ParseMoney(params.Get("threshold"))
+	if err != nil || threshold <= 0 {
+		return "invalid_threshold", nil
+	}
+
+	billing := s.clientBilling(s.clients[i].Client)
+	billing.threshold.Threshold = threshold
+	if email := params.Get("email"); email != "" {
+		billing.threshold.Email = email
+	}
+	s.clients[i].Threshold = threshold
+
+	return "success", nil
+}
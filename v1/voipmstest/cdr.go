@@ -0,0 +1,90 @@
+package voipmstest
+
+import (
+	url2 "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+// AddCDR records a call. Its date is taken as UTC and converted to the
+// timezone requested by getCDR.
+func (s *Server) AddCDR(cdr v1.CDR) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.cdrs = append(s.cdrs, cdr)
+}
+
+func (s *Server) getCDR(params url2.Values) (string, map[string]interface{}) {
+	var (
+		from    v1.VoIpMsDate
+		to      v1.VoIpMsDate
+		records []map[string]interface{}
+	)
+
+	if params.Get("date_from") == "" || params.Get("date_to") == "" || params.Get("timezone") == "" {
+		return "missing_params", nil
+	}
+
+	if from.UnmarshalText([]byte(params.Get("date_from"))) != nil || to.UnmarshalText([]byte(params.Get("date_to"))) != nil {
+		return "invalid_date", nil
+	}
+
+	if to.Before(from.Time) || to.Sub(from.Time) >= v1.MaxCDRWindowDays*24*time.Hour {
+		return "invalid_daterange", nil
+	}
+
+	timezone, err := strconv.ParseFloat(params.Get("timezone"), 64)
+	if err != nil {
+		return "invalid_timezone", nil
+	}
+	location := time.FixedZone("", int(timezone*3600))
+
+	dispositions := map[string]bool{
+		"ANSWERED":  params.Get("answered") == "1",
+		"NO ANSWER": params.Get("noanswer") == "1",
+		"BUSY":      params.Get("busy") == "1",
+		"FAILED":    params.Get("failed") == "1",
+	}
+
+	account := params.Get("account")
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, location).AddDate(0, 0, 1)
+
+	for _, cdr := range s.cdrs {
+		date := time.Date(cdr.Date.Year(), cdr.Date.Month(), cdr.Date.Day(), cdr.Date.Hour(), cdr.Date.Minute(), cdr.Date.Second(), 0, time.UTC)
+		if date.Before(start) || !date.Before(end) {
+			continue
+		}
+		if !dispositions[strings.ToUpper(cdr.Disposition)] {
+			continue
+		}
+		if account != "" && account != "all" && cdr.Account != account {
+			continue
+		}
+
+		records = append(records, map[string]interface{}{
+			"date":             date.In(location).Format("2006-01-02 15:04:05"),
+			"callerid":         cdr.CallerID,
+			"destination":      cdr.Destination,
+			"description":      cdr.Description,
+			"account":          cdr.Account,
+			"disposition":      cdr.Disposition,
+			"duration":         cdr.Duration,
+			"seconds":          cdr.Seconds,
+			"rate":             cdr.Rate,
+			"total":            cdr.Total,
+			"uniqueid":         cdr.UniqueID,
+			"destination_type": cdr.DestinationType,
+		})
+	}
+
+	if len(records) == 0 {
+		return "no_cdr", nil
+	}
+
+	return "success", map[string]interface{}{"cdr": records}
+}
@@ -0,0 +1,285 @@
+package voipmstest
+
+import (
+	url2 "net/url"
+	"strings"
+	"time"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+// AddAvailableDID offers did for ordering; Province is set for Canadian
+// numbers and State for American ones.
+func (s *Server) AddAvailableDID(did v1.AvailableDID) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.availableDIDs = append(s.availableDIDs, did)
+}
+
+func (s *Server) AddTollFreeDID(did v1.TollFreeDID) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.tollFreeDIDs = append(s.tollFreeDIDs, did)
+}
+
+func (s *Server) AddProvince(province v1.Province) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.provinces = append(s.provinces, province)
+}
+
+func (s *Server) AddState(state v1.State) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.states = append(s.states, state)
+}
+
+func (s *Server) getProvinces(_ url2.Values) (string, map[string]interface{}) {
+	return "success", map[string]interface{}{"provinces": s.provinces}
+}
+
+func (s *Server) getStates(_ url2.Values) (string, map[string]interface{}) {
+	return "success", map[string]interface{}{"states": s.states}
+}
+
+// rateCenters lists the rate centers having available DIDs in region,
+// matched against the province or state with region.
+func (s *Server) rateCenters(region func(did v1.AvailableDID) bool) []v1.RateCenter {
+	rateCenters := []v1.RateCenter{}
+	seen := map[string]bool{}
+
+	for _, did := range s.availableDIDs {
+		if region(did) && !seen[did.RateCenter] {
+			seen[did.RateCenter] = true
+			rateCenters = append(rateCenters, v1.RateCenter{RateCenter: did.RateCenter, Available: "yes"})
+		}
+	}
+
+	return rateCenters
+}
+
+func (s *Server) getRateCentersCAN(params url2.Values) (string, map[string]interface{}) {
+	province := params.Get("province")
+	if province == "" {
+		return "missing_province", nil
+	}
+
+	found := false
+	for _, known := range s.provinces {
+		found = found || known.Province == province
+	}
+	if !found {
+		return "invalid_province", nil
+	}
+
+	return "success", map[string]interface{}{"ratecenters": s.rateCenters(func(did v1.AvailableDID) bool {
+		return did.Province == province
+	})}
+}
+
+func (s *Server) getRateCentersUSA(params url2.Values) (string, map[string]interface{}) {
+	state := params.Get("state")
+	if state == "" {
+		return "missing_state", nil
+	}
+
+	found := false
+	for _, known := range s.states {
+		found = found || known.State == state
+	}
+	if !found {
+		return "invalid_state", nil
+	}
+
+	return "success", map[string]interface{}{"ratecenters": s.rateCenters(func(did v1.AvailableDID) bool {
+		return did.State == state
+	})}
+}
+
+// matchSearch applies the type and query parameters of the search calls to
+// did, returning the error status for invalid parameters.
+func matchSearch(params url2.Values, did string) (bool, string) {
+	query := params.Get("query")
+	if query == "" {
+		return false, "missing_query"
+	}
+
+	switch v1.DIDSearchType(params.Get("type")) {
+	case v1.DIDSearchStarts:
+		return strings.HasPrefix(did, query), ""
+	case v1.DIDSearchContains:
+		return strings.Contains(did, query), ""
+	case v1.DIDSearchEnds:
+		return strings.HasSuffix(did, query), ""
+	case "":
+		return false, "missing_type"
+	}
+
+	return false, "invalid_type"
+}
+
+func (s *Server) searchDIDs(params url2.Values, region func(did v1.AvailableDID) bool) (string, map[string]interface{}) {
+	var dids []v1.AvailableDID
+
+	if _, status := matchSearch(params, ""); status != "" {
+		return status, nil
+	}
+
+	for _, did := range s.availableDIDs {
+		if match, _ := matchSearch(params, did.DID); match && region(did) {
+			dids = append(dids, did)
+		}
+	}
+
+	if len(dids) == 0 {
+		return "no_did", nil
+	}
+
+	return "success", map[string]interface{}{"dids": dids}
+}
+
+func (s *Server) searchDIDsCAN(params url2.Values) (string, map[string]interface{}) {
+	province := params.Get("province")
+	return s.searchDIDs(params, func(did v1.AvailableDID) bool {
+		return did.Province != "" && (province == "" || did.Province == province)
+	})
+}
+
+func (s *Server) searchDIDsUSA(params url2.Values) (string, map[string]interface{}) {
+	state := params.Get("state")
+	return s.searchDIDs(params, func(did v1.AvailableDID) bool {
+		return did.State != "" && (state == "" || did.State == state)
+	})
+}
+
+func (s *Server) searchTollFreeCanUS(params url2.Values) (string, map[string]interface{}) {
+	var dids []v1.TollFreeDID
+
+	if _, status := matchSearch(params, ""); status != "" {
+		return status, nil
+	}
+
+	for _, did := range s.tollFreeDIDs {
+		if match, _ := matchSearch(params, did.DID); match {
+			dids = append(dids, did)
+		}
+	}
+
+	if len(dids) == 0 {
+		return "no_did", nil
+	}
+
+	return "success", map[string]interface{}{"dids": dids}
+}
+
+// placeOrder validates the settings of an order and, unless the test
+// parameter is set, adds did to the account.
+func (s *Server) placeOrder(did string, params url2.Values) (string, map[string]interface{}) {
+	info := v1.DIDInfo{
+		DID:       did,
+		OrderDate: v1.VoIpMsDateTime{Time: time.Now().UTC().Truncate(time.Second)},
+	}
+
+	if status := s.applyDIDSettings(&info, params); status != "" {
+		return status, nil
+	}
+
+	client := params.Get("account")
+	if client != "" && s.findClient(client) < 0 {
+		return "invalid_account", nil
+	}
+
+	if params.Get("test") == "1" {
+		return "success", nil
+	}
+
+	s.dids = append(s.dids, info)
+	s.didClients[did] = client
+
+	return "success", map[string]interface{}{"did": did}
+}
+
+func (s *Server) orderDID(params url2.Values) (string, map[string]interface{}) {
+	did := params.Get("did")
+	if did == "" {
+		return "missing_did", nil
+	}
+
+	for i := range s.availableDIDs {
+		if s.availableDIDs[i].DID != did {
+			continue
+		}
+
+		status, payload := s.placeOrder(did, params)
+		if status == "success" && params.Get("test") != "1" {
+			s.availableDIDs = append(s.availableDIDs[:i], s.availableDIDs[i+1:]...)
+		}
+		return status, payload
+	}
+
+	return "did_unavailable", nil
+}
+
+func (s *Server) orderTollFree(params url2.Values) (string, map[string]interface{}) {
+	did := params.Get("did")
+	if did == "" {
+		return "missing_did", nil
+	}
+
+	for i := range s.tollFreeDIDs {
+		if s.tollFreeDIDs[i].DID != did {
+			continue
+		}
+
+		status, payload := s.placeOrder(did, params)
+		if status == "success" && params.Get("test") != "1" {
+			s.tollFreeDIDs = append(s.tollFreeDIDs[:i], s.tollFreeDIDs[i+1:]...)
+		}
+		return status, payload
+	}
+
+	return "did_unavailable", nil
+}
+
+func (s *Server) orderDIDVirtual(params url2.Values) (string, map[string]interface{}) {
+	digits := params.Get("digits")
+	if digits == "" {
+		return "missing_digits", nil
+	}
+
+	if len(digits) != 3 || strings.Trim(digits, "0123456789") != "" {
+		return "invalid_digits", nil
+	}
+
+	did := s.AccountID + digits
+	if s.findDID(did) >= 0 {
+		return "did_unavailable", nil
+	}
+
+	return s.placeOrder(did, params)
+}
+
+func (s *Server) cancelDID(params url2.Values) (string, map[string]interface{}) {
+	did := params.Get("did")
+	if did == "" {
+		return "missing_did", nil
+	}
+
+	i := s.findDID(did)
+	if i < 0 {
+		return "invalid_did", nil
+	}
+
+	if params.Get("test") == "1" {
+		return "success", nil
+	}
+
+	s.dids = append(s.dids[:i], s.dids[i+1:]...)
+	delete(s.didClients, did)
+
+	return "success", nil
+}
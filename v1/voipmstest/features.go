@@ -0,0 +1,94 @@
+package voipmstest
+
+import (
+	url2 "net/url"
+	"strconv"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func (s *Server) AddForwarding(forwarding v1.Forwarding) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.forwardings = append(s.forwardings, forwarding)
+}
+
+func (s *Server) AddRingGroup(ringGroup v1.RingGroup) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.ringGroups = append(s.ringGroups, ringGroup)
+}
+
+func (s *Server) AddIVR(ivr v1.IVR) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.ivrs = append(s.ivrs, ivr)
+}
+
+// matchID tells whether id, an optional filter parameter, selects value.
+func matchID(id string, value v1.VoIpMsStringInt) bool {
+	return id == "" || id == strconv.FormatInt(int64(value), 10)
+}
+
+func (s *Server) getForwardings(params url2.Values) (string, map[string]interface{}) {
+	forwardings := []v1.Forwarding{}
+
+	id := params.Get("forwarding")
+	for _, forwarding := range s.forwardings {
+		if matchID(id, forwarding.Forwarding) {
+			forwardings = append(forwardings, forwarding)
+		}
+	}
+
+	if len(forwardings) == 0 {
+		if id != "" {
+			return "invalid_forwarding", nil
+		}
+		return "no_forwarding", nil
+	}
+
+	return "success", map[string]interface{}{"forwardings": forwardings}
+}
+
+func (s *Server) getRingGroups(params url2.Values) (string, map[string]interface{}) {
+	ringGroups := []v1.RingGroup{}
+
+	id := params.Get("ring_group")
+	for _, ringGroup := range s.ringGroups {
+		if matchID(id, ringGroup.RingGroup) {
+			ringGroups = append(ringGroups, ringGroup)
+		}
+	}
+
+	if len(ringGroups) == 0 {
+		if id != "" {
+			return "invalid_ring_group", nil
+		}
+		return "no_ring_group", nil
+	}
+
+	return "success", map[string]interface{}{"ring_groups": ringGroups}
+}
+
+func (s *Server) getIVRs(params url2.Values) (string, map[string]interface{}) {
+	ivrs := []v1.IVR{}
+
+	id := params.Get("ivr")
+	for _, ivr := range s.ivrs {
+		if matchID(id, ivr.IVR) {
+			ivrs = append(ivrs, ivr)
+		}
+	}
+
+	if len(ivrs) == 0 {
+		if id != "" {
+			return "invalid_ivr", nil
+		}
+		return "no_ivr", nil
+	}
+
+	return "success", map[string]interface{}{"ivrs": ivrs}
+}
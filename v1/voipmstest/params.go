@@ -0,0 +1,79 @@
+package voipmstest
+
+import (
+	"encoding"
+	url2 "net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// applyParams copies the request parameters present in params into the
+// fields of target sharing the same url tag, the reverse of what the v1
+// client does when encoding a request.
+func applyParams(target interface{}, params url2.Values) {
+	v := reflect.ValueOf(target).Elem()
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		tag := field.Tag.Get("url")
+
+		if tag == "" && field.Anonymous {
+			applyParams(v.Field(i).Addr().Interface(), params)
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		if _, ok := params[name]; !ok {
+			continue
+		}
+
+		setParam(v.Field(i), params.Get(name), strings.Contains(tag, ",semicolon"))
+	}
+}
+
+func setParam(field reflect.Value, value string, semicolon bool) {
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok && field.Kind() != reflect.Bool {
+		_ = unmarshaler.UnmarshalText([]byte(value))
+		return
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		field.SetBool(value == "1" || strings.EqualFold(value, "yes") || value == "true")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			field.SetInt(i)
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			field.SetFloat(f)
+		}
+	case reflect.Slice:
+		items := []string{value}
+		if semicolon {
+			items = strings.Split(value, ";")
+		}
+		slice := reflect.MakeSlice(field.Type(), 0, len(items))
+		for _, item := range items {
+			if item == "" {
+				continue
+			}
+			element := reflect.New(field.Type().Elem()).Elem()
+			setParam(element, item, false)
+			slice = reflect.Append(slice, element)
+		}
+		field.Set(slice)
+	}
+}
@@ -0,0 +1,606 @@
+// Package voipmstest provides an in-memory VoIP.ms REST API for tests.
+//
+//	fake := voipmstest.NewServer("user@example.com", "secret")
+//	defer fake.Close()
+//	vms := &v1.VoIpMsApi{ApiUsername: "user@example.com", ApiPassword: "secret", ApiUrl: fake.URL}
+package voipmstest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	url2 "net/url"
+	"strconv"
+	"sync"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+type Server struct {
+	*httptest.Server
+	Username  string
+	Password  string
+	AccountID string
+
+	mutex         sync.Mutex
+	dids          []v1.DIDInfo
+	didClients    map[string]string
+	servers       []v1.ServerInfo
+	registrations map[string][]v1.RegistrationStatus
+	clients       []v1.ClientInfo
+	billing       map[string]*clientBilling
+	subAccounts   []v1.SubAccount
+	balance       v1.Balance
+	transactions  []v1.Transaction
+	cdrs          []v1.CDR
+	messages      []v1.Message
+	forwardings   []v1.Forwarding
+	ringGroups    []v1.RingGroup
+	ivrs          []v1.IVR
+	availableDIDs []v1.AvailableDID
+	tollFreeDIDs  []v1.TollFreeDID
+	provinces     []v1.Province
+	states        []v1.State
+	statusErrors  map[string][]string
+	httpErrors    map[string][]int
+	requests      []url2.Values
+
+	nextSubAccountID int
+	nextMessageID    int
+}
+
+type handlerFunc func(params url2.Values) (status string, payload map[string]interface{})
+
+func NewServer(username string, password string) *Server {
+	s := &Server{
+		Username:      username,
+		Password:      password,
+		AccountID:     "100000",
+		didClients:    map[string]string{},
+		registrations: map[string][]v1.RegistrationStatus{},
+		billing:       map[string]*clientBilling{},
+		statusErrors:  map[string][]string{},
+		httpErrors:    map[string][]int{},
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Client returns a VoIpMsApi configured to talk to the fake server.
+func (s *Server) Client() *v1.VoIpMsApi {
+	return &v1.VoIpMsApi{
+		ApiUsername: s.Username,
+		ApiPassword: s.Password,
+		ApiUrl:      s.URL,
+		HttpClient:  s.Server.Client(),
+	}
+}
+
+func (s *Server) AddDID(did v1.DIDInfo) {
+	s.AddClientDID("", did)
+}
+
+func (s *Server) AddClientDID(client string, did v1.DIDInfo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.dids = append(s.dids, did)
+	s.didClients[did.DID] = client
+}
+
+func (s *Server) DID(did string) (v1.DIDInfo, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if i := s.findDID(did); i >= 0 {
+		return s.dids[i], true
+	}
+	return v1.DIDInfo{}, false
+}
+
+func (s *Server) AddServer(server v1.ServerInfo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.servers = append(s.servers, server)
+}
+
+func (s *Server) AddRegistration(account string, registration v1.RegistrationStatus) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registration.Account = account
+	s.registrations[account] = append(s.registrations[account], registration)
+}
+
+// AddAccount declares a sub-account that exists but is not registered.
+func (s *Server) AddAccount(account string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.registrations[account]; !ok {
+		s.registrations[account] = []v1.RegistrationStatus{}
+	}
+}
+
+func (s *Server) AddClient(client v1.ClientInfo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.clients = append(s.clients, client)
+}
+
+// FailNext makes the next call to apiMethod answer with the given VoIP.ms
+// status. Calls are queued, so FailNext can be used several times in a row.
+func (s *Server) FailNext(apiMethod string, status string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.statusErrors[apiMethod] = append(s.statusErrors[apiMethod], status)
+}
+
+// FailNextHTTP makes the next call to apiMethod answer with an HTTP error.
+func (s *Server) FailNextHTTP(apiMethod string, statusCode int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.httpErrors[apiMethod] = append(s.httpErrors[apiMethod], statusCode)
+}
+
+// Requests returns the parameters of every request received so far.
+func (s *Server) Requests() []url2.Values {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]url2.Values(nil), s.requests...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	params := r.Form
+	method := params.Get("method")
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requests = append(s.requests, params)
+
+	if codes := s.httpErrors[method]; len(codes) > 0 {
+		s.httpErrors[method] = codes[1:]
+		w.WriteHeader(codes[0])
+		return
+	}
+
+	status, payload := s.dispatch(method, params)
+
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+	payload["status"] = status
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(toWire(payload))
+}
+
+func (s *Server) dispatch(method string, params url2.Values) (string, map[string]interface{}) {
+	username := params.Get("api_username")
+	password := params.Get("api_password")
+
+	if username == "" || password == "" {
+		return "missing_credentials", nil
+	}
+
+	if username != s.Username || password != s.Password {
+		return "invalid_credentials", nil
+	}
+
+	if method == "" {
+		return "missing_method", nil
+	}
+
+	if statuses := s.statusErrors[method]; len(statuses) > 0 {
+		s.statusErrors[method] = statuses[1:]
+		return statuses[0], nil
+	}
+
+	handlers := map[string]handlerFunc{
+		"getDIDsInfo":           s.getDIDsInfo,
+		"setDIDPOP":             s.setDIDPOP,
+		"setDIDInfo":            s.setDIDInfo,
+		"setDIDRouting":         s.setDIDRouting,
+		"getForwardings":        s.getForwardings,
+		"getRingGroups":         s.getRingGroups,
+		"getIVRs":               s.getIVRs,
+		"getProvinces":          s.getProvinces,
+		"getStates":             s.getStates,
+		"getRateCentersCAN":     s.getRateCentersCAN,
+		"getRateCentersUSA":     s.getRateCentersUSA,
+		"searchDIDsCAN":         s.searchDIDsCAN,
+		"searchDIDsUSA":         s.searchDIDsUSA,
+		"searchTollFreeCanUS":   s.searchTollFreeCanUS,
+		"orderDID":              s.orderDID,
+		"orderTollFree":         s.orderTollFree,
+		"orderDIDVirtual":       s.orderDIDVirtual,
+		"cancelDID":             s.cancelDID,
+		"getServersInfo":        s.getServersInfo,
+		"getRegistrationStatus": s.getRegistrationStatus,
+		"getClients":            s.getClients,
+		"addClient":             s.addClient,
+		"signupClient":          s.signupClient,
+		"setClient":             s.setClient,
+		"getResellerBalance":    s.getResellerBalance,
+		"addCharge":             s.addCharge,
+		"addPayment":            s.addPayment,
+		"getCharges":            s.getCharges,
+		"getDeposits":           s.getDeposits,
+		"getClientPackages":     s.getClientPackages,
+		"getClientThreshold":    s.getClientThreshold,
+		"setClientThreshold":    s.setClientThreshold,
+		"getSubAccounts":        s.getSubAccounts,
+		"createSubAccount":      s.createSubAccount,
+		"setSubAccount":         s.setSubAccount,
+		"delSubAccount":         s.delSubAccount,
+		"getBalance":            s.getBalance,
+		"getTransactionHistory": s.getTransactionHistory,
+		"getCDR":                s.getCDR,
+		"sendSMS":               s.sendSMS,
+		"sendMMS":               s.sendMMS,
+		"getSMS":                s.getSMS,
+		"getMMS":                s.getMMS,
+		"deleteSMS":             s.deleteSMS,
+		"setSMS":                s.setSMS,
+	}
+
+	if handler, ok := handlers[method]; ok {
+		return handler(params)
+	}
+
+	return "invalid_method", nil
+}
+
+func (s *Server) findDID(did string) int {
+	for i := range s.dids {
+		if s.dids[i].DID == did {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) getDIDsInfo(params url2.Values) (string, map[string]interface{}) {
+	var dids []v1.DIDInfo
+
+	client := params.Get("client")
+	did := params.Get("did")
+
+	for _, info := range s.dids {
+		if client != "" && s.didClients[info.DID] != client {
+			continue
+		}
+		if did != "" && info.DID != did {
+			continue
+		}
+		dids = append(dids, info)
+	}
+
+	if len(dids) == 0 {
+		if did != "" {
+			return "invalid_did", nil
+		}
+		return "no_did", nil
+	}
+
+	return "success", map[string]interface{}{"dids": dids}
+}
+
+func (s *Server) setDIDPOP(params url2.Values) (string, map[string]interface{}) {
+	did := params.Get("did")
+	pop := params.Get("pop")
+
+	if did == "" {
+		return "missing_did", nil
+	}
+
+	if pop == "" {
+		return "missing_pop", nil
+	}
+
+	i := s.findDID(did)
+	if i < 0 {
+		return "invalid_did", nil
+	}
+
+	popNumber, err := strconv.ParseInt(pop, 10, 64)
+	if err != nil || s.findServer(popNumber) < 0 {
+		return "invalid_pop", nil
+	}
+
+	s.dids[i].Pop = v1.VoIpMsStringInt(popNumber)
+	return "success", nil
+}
+
+func (s *Server) findServer(pop int64) int {
+	for i := range s.servers {
+		if int64(s.servers[i].ServerPOP) == pop {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) getServersInfo(params url2.Values) (string, map[string]interface{}) {
+	var servers []v1.ServerInfo
+
+	if pop := params.Get("server_pop"); pop != "" {
+		popNumber, err := strconv.ParseInt(pop, 10, 64)
+		if err != nil {
+			return "invalid_server_pop", nil
+		}
+		i := s.findServer(popNumber)
+		if i < 0 {
+			return "invalid_server_pop", nil
+		}
+		servers = append(servers, s.servers[i])
+	} else {
+		servers = append(servers, s.servers...)
+	}
+
+	if len(servers) == 0 {
+		return "no_servers", nil
+	}
+
+	return "success", map[string]interface{}{"servers": servers}
+}
+
+func (s *Server) getRegistrationStatus(params url2.Values) (string, map[string]interface{}) {
+	account := params.Get("account")
+
+	if account == "" {
+		return "missing_account", nil
+	}
+
+	registrations, ok := s.registrations[account]
+	if !ok {
+		return "invalid_account", nil
+	}
+
+	registered := "no"
+	if len(registrations) > 0 {
+		registered = "yes"
+	}
+
+	return "success", map[string]interface{}{
+		"registered":    registered,
+		"registrations": registrations,
+	}
+}
+
+func (s *Server) getClients(params url2.Values) (string, map[string]interface{}) {
+	var clients []v1.ClientInfo
+
+	client := params.Get("client")
+
+	for _, info := range s.clients {
+		if client != "" && info.Client != client {
+			continue
+		}
+		clients = append(clients, info)
+	}
+
+	if len(clients) == 0 {
+		if client != "" {
+			return "invalid_client", nil
+		}
+		return "no_client", nil
+	}
+
+	return "success", map[string]interface{}{"clients": clients}
+}
+
+func applyClientParams(client *v1.ClientInfo, params url2.Values) {
+	fields := map[string]*string{
+		"firstname":    &client.FirstName,
+		"lastname":     &client.LastName,
+		"company":      &client.Company,
+		"address":      &client.Address,
+		"city":         &client.City,
+		"state":        &client.State,
+		"country":      &client.Country,
+		"zip":          &client.Zip,
+		"phone_number": &client.PhoneNumber,
+		"email":        &client.Email,
+		"password":     &client.Password,
+	}
+
+	for name, field := range fields {
+		if _, ok := params[name]; ok {
+			*field = params.Get(name)
+		}
+	}
+
+	if balanceManagement, err := strconv.ParseInt(params.Get("balance_management"), 10, 64); err == nil {
+		client.BalanceManagement = v1.VoIpMsStringInt(balanceManagement)
+	}
+}
+
+func (s *Server) findClient(client string) int {
+	for i := range s.clients {
+		if s.clients[i].Client == client {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) addClient(params url2.Values) (string, map[string]interface{}) {
+	if params.Get("email") == "" {
+		return "missing_email", nil
+	}
+
+	for _, client := range s.clients {
+		if client.Email == params.Get("email") {
+			return "used_email", nil
+		}
+	}
+
+	id := int64(100000)
+	for _, client := range s.clients {
+		if existing, err := strconv.ParseInt(client.Client, 10, 64); err == nil && existing >= id {
+			id = existing + 1
+		}
+	}
+
+	client := v1.ClientInfo{Client: strconv.FormatInt(id, 10), Status: "active"}
+	applyClientParams(&client, params)
+	s.clients = append(s.clients, client)
+
+	return "success", map[string]interface{}{"client": client.Client}
+}
+
+func (s *Server) signupClient(params url2.Values) (string, map[string]interface{}) {
+	if params.Get("confirm_email") != params.Get("email") {
+		return "invalid_confirm_email", nil
+	}
+
+	if params.Get("confirm_password") != params.Get("password") {
+		return "invalid_confirm_password", nil
+	}
+
+	status, payload := s.addClient(params)
+	if status == "success" && params.Get("activate") != "1" {
+		s.clients[len(s.clients)-1].Status = "inactive"
+	}
+
+	return status, payload
+}
+
+func (s *Server) setClient(params url2.Values) (string, map[string]interface{}) {
+	client := params.Get("client")
+	if client == "" {
+		return "missing_client", nil
+	}
+
+	i := s.findClient(client)
+	if i < 0 {
+		return "invalid_client", nil
+	}
+
+	applyClientParams(&s.clients[i], params)
+	return "success", nil
+}
+
+func validRouting(routing string) bool {
+	target, err := v1.ParseRoutingTarget(routing)
+	return err == nil && target.Validate() == nil
+}
+
+func (s *Server) setDIDRouting(params url2.Values) (string, map[string]interface{}) {
+	did := params.Get("did")
+	routing := params.Get("routing")
+
+	if did == "" {
+		return "missing_did", nil
+	}
+
+	if routing == "" {
+		return "missing_routing", nil
+	}
+
+	i := s.findDID(did)
+	if i < 0 {
+		return "invalid_did", nil
+	}
+
+	if !validRouting(routing) {
+		return "invalid_routing", nil
+	}
+
+	s.dids[i].Routing = v1.MustParseRoutingTarget(routing)
+	return "success", nil
+}
+
+func (s *Server) setDIDInfo(params url2.Values) (string, map[string]interface{}) {
+	did := params.Get("did")
+	if did == "" {
+		return "missing_did", nil
+	}
+
+	i := s.findDID(did)
+	if i < 0 {
+		return "invalid_did", nil
+	}
+
+	info := s.dids[i]
+	if status := s.applyDIDSettings(&info, params); status != "" {
+		return status, nil
+	}
+
+	s.dids[i] = info
+	return "success", nil
+}
+
+// applyDIDSettings validates and copies the settings shared by setDIDInfo
+// and the order calls onto info, returning the error status on failure.
+func (s *Server) applyDIDSettings(info *v1.DIDInfo, params url2.Values) string {
+	for _, name := range []string{"routing", "pop", "dialtime", "cnam", "billing_type"} {
+		if params.Get(name) == "" {
+			return "missing_" + name
+		}
+	}
+
+	if !validRouting(params.Get("routing")) {
+		return "invalid_routing"
+	}
+
+	for _, name := range []string{"failover_busy", "failover_unreachable", "failover_noanswer"} {
+		if failover := params.Get(name); failover != "" && !validRouting(failover) {
+			return "invalid_" + name
+		}
+	}
+
+	popNumber, err := strconv.ParseInt(params.Get("pop"), 10, 64)
+	if err != nil || s.findServer(popNumber) < 0 {
+		return "invalid_pop"
+	}
+
+	// setDIDInfo replaces every setting, missing parameters are cleared.
+	var settings struct {
+		Routing             v1.RoutingTarget `url:"routing"`
+		FailoverBusy        v1.RoutingTarget `url:"failover_busy"`
+		FailoverUnreachable v1.RoutingTarget `url:"failover_unreachable"`
+		FailoverNoAnswer    v1.RoutingTarget `url:"failover_noanswer"`
+		Voicemail           string           `url:"voicemail"`
+		Dialtime            int64            `url:"dialtime"`
+		CNAM                bool             `url:"cnam"`
+		CallerIDPrefix      string           `url:"callerid_prefix"`
+		Note                string           `url:"note"`
+		BillingType         int64            `url:"billing_type"`
+		RecordCalls         bool             `url:"record_calls"`
+		Transcribe          bool             `url:"transcribe"`
+		TranscriptionLocale string           `url:"transcription_locale"`
+		TranscriptionEmail  string           `url:"transcription_email"`
+	}
+	applyParams(&settings, params)
+
+	info.Routing = settings.Routing
+	info.FailoverBusy = settings.FailoverBusy
+	info.FailoverUnreachable = settings.FailoverUnreachable
+	info.FailoverNoAnswer = settings.FailoverNoAnswer
+	info.Voicemail = settings.Voicemail
+	info.Pop = v1.VoIpMsStringInt(popNumber)
+	info.Dialtime = v1.VoIpMsStringInt(settings.Dialtime)
+	info.CNAM = boolInt(settings.CNAM)
+	info.CallerIDPrefix = settings.CallerIDPrefix
+	info.Note = settings.Note
+	info.BillingType = v1.VoIpMsStringInt(settings.BillingType)
+	info.RecordCalls = boolInt(settings.RecordCalls)
+	info.Transcribe = boolInt(settings.Transcribe)
+	info.TranscriptionLocale = settings.TranscriptionLocale
+	info.TranscriptionEmail = settings.TranscriptionEmail
+
+	return ""
+}
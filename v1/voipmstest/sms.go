@@ -0,0 +1,185 @@
+package voipmstest
+
+import (
+	url2 "net/url"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+// AddMessage stores a message as if it had been received or sent by a DID.
+// MMS are told apart from SMS by having media.
+func (s *Server) AddMessage(message v1.Message) v1.Message {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.addMessage(message)
+}
+
+func (s *Server) addMessage(message v1.Message) v1.Message {
+	s.nextMessageID++
+	message.ID = v1.VoIpMsStringInt(s.nextMessageID)
+	if message.Date.IsZero() {
+		message.Date = v1.VoIpMsDateTime{Time: time.Now().UTC().Truncate(time.Second)}
+	}
+	s.messages = append(s.messages, message)
+	return message
+}
+
+// Messages returns every message stored or sent so far.
+func (s *Server) Messages() []v1.Message {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]v1.Message(nil), s.messages...)
+}
+
+func (s *Server) sendMessage(params url2.Values, maxLength int, media []string) (string, v1.Message) {
+	did := params.Get("did")
+	dst := params.Get("dst")
+	message := params.Get("message")
+
+	switch {
+	case did == "":
+		return "missing_did", v1.Message{}
+	case s.findDID(did) < 0:
+		return "invalid_did", v1.Message{}
+	case dst == "":
+		return "missing_dst", v1.Message{}
+	case len(dst) != 10:
+		return "invalid_dst", v1.Message{}
+	case message == "" && len(media) == 0:
+		return "missing_message", v1.Message{}
+	case utf8.RuneCountInString(message) > maxLength:
+		if maxLength == v1.SMSMaxLength {
+			return "sms_toolong", v1.Message{}
+		}
+		return "mms_toolong", v1.Message{}
+	}
+
+	return "success", s.addMessage(v1.Message{
+		Type:    v1.MessageSent,
+		DID:     did,
+		Contact: dst,
+		Message: message,
+		Media:   media,
+	})
+}
+
+func (s *Server) sendSMS(params url2.Values) (string, map[string]interface{}) {
+	status, message := s.sendMessage(params, v1.SMSMaxLength, nil)
+	if status != "success" {
+		return status, nil
+	}
+	return status, map[string]interface{}{"sms": message.ID}
+}
+
+func (s *Server) sendMMS(params url2.Values) (string, map[string]interface{}) {
+	var media []string
+	for _, name := range []string{"media1", "media2", "media3"} {
+		if value := params.Get(name); value != "" {
+			media = append(media, value)
+		}
+	}
+
+	status, message := s.sendMessage(params, v1.MMSMaxLength, media)
+	if status != "success" {
+		return status, nil
+	}
+	return status, map[string]interface{}{"mms": message.ID}
+}
+
+func (s *Server) getMessages(params url2.Values, idParam string, mms bool) (string, map[string]interface{}) {
+	var (
+		from     v1.VoIpMsDate
+		to       v1.VoIpMsDate
+		messages []v1.Message
+	)
+
+	if from.UnmarshalText([]byte(params.Get("from"))) != nil || to.UnmarshalText([]byte(params.Get("to"))) != nil {
+		return "invalid_date", nil
+	}
+
+	// Messages are stored in UTC and listed in the timezone asked for, UTC
+	// when none is.
+	location := time.UTC
+	if timezone := params.Get("timezone"); timezone != "" {
+		hours, err := strconv.ParseFloat(timezone, 64)
+		if err != nil {
+			return "invalid_timezone", nil
+		}
+		location = time.FixedZone("", int(hours*3600))
+	}
+
+	limit, _ := strconv.Atoi(params.Get("limit"))
+
+	for _, message := range s.messages {
+		if (len(message.Media) > 0) != mms {
+			continue
+		}
+		if id := params.Get(idParam); id != "" && strconv.FormatInt(int64(message.ID), 10) != id {
+			continue
+		}
+		if messageType := params.Get("type"); messageType != "" && strconv.FormatInt(int64(message.Type), 10) != messageType {
+			continue
+		}
+		if did := params.Get("did"); did != "" && message.DID != did {
+			continue
+		}
+		if contact := params.Get("contact"); contact != "" && message.Contact != contact {
+			continue
+		}
+		if !from.IsZero() && message.Date.Before(time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)) {
+			continue
+		}
+		if !to.IsZero() && !message.Date.Before(time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, location).AddDate(0, 0, 1)) {
+			continue
+		}
+		message.Date.Time = message.Date.In(location)
+		messages = append(messages, message)
+	}
+
+	// VoIP.ms lists the most recent messages first.
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	if limit > 0 && len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	if len(messages) == 0 {
+		if mms {
+			return "no_mms", nil
+		}
+		return "no_sms", nil
+	}
+
+	return "success", map[string]interface{}{"sms": messages}
+}
+
+func (s *Server) getSMS(params url2.Values) (string, map[string]interface{}) {
+	return s.getMessages(params, "sms", false)
+}
+
+func (s *Server) getMMS(params url2.Values) (string, map[string]interface{}) {
+	return s.getMessages(params, "mms", true)
+}
+
+func (s *Server) deleteSMS(params url2.Values) (string, map[string]interface{}) {
+	id := params.Get("id")
+	if id == "" {
+		return "missing_id", nil
+	}
+
+	for i, message := range s.messages {
+		if strconv.FormatInt(int64(message.ID), 10) == id {
+			s.messages = append(s.messages[:i], s.messages[i+1:]...)
+			return "success", nil
+		}
+	}
+
+	return "invalid_id", nil
+}
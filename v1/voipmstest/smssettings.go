@@ -0,0 +1,54 @@
+package voipmstest
+
+import (
+	url2 "net/url"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func boolInt(value bool) v1.VoIpMsStringInt {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func (s *Server) setSMS(params url2.Values) (string, map[string]interface{}) {
+	did := params.Get("did")
+	if did == "" {
+		return "missing_did", nil
+	}
+
+	i := s.findDID(did)
+	if i < 0 {
+		return "invalid_did", nil
+	}
+
+	if s.dids[i].SMSAvailable == 0 {
+		return "sms_not_available", nil
+	}
+
+	if _, ok := params["enable"]; !ok {
+		return "missing_enable", nil
+	}
+
+	// setSMS replaces every setting, missing parameters are cleared.
+	var settings v1.SMSSettings
+	applyParams(&settings, params)
+
+	info := &s.dids[i]
+	info.SMSEnabled = boolInt(settings.Enabled)
+	info.SMSEmailEnabled = boolInt(settings.EmailEnabled)
+	info.SMSEmail = settings.Email
+	info.SMSForwardEnabled = boolInt(settings.ForwardEnabled)
+	info.SMSForward = settings.Forward
+	info.SMSURLCallbackEnabled = boolInt(settings.URLCallbackEnabled)
+	info.SMSURLCallback = settings.URLCallback
+	info.SMSURLCallbackRetry = boolInt(settings.URLCallbackRetry)
+	info.SMPPE = boolInt(settings.SMPPEnabled)
+	info.SMPPURL = settings.SMPPURL
+	info.SMPPUser = settings.SMPPUser
+	info.SMPPPass = settings.SMPPPass
+
+	return "success", nil
+}
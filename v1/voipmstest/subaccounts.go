@@ -0,0 +1,126 @@
+package voipmstest
+
+import (
+	url2 "net/url"
+	"strconv"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+func (s *Server) AddSubAccount(account v1.SubAccount) v1.SubAccount {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.addSubAccount(account)
+}
+
+func (s *Server) addSubAccount(account v1.SubAccount) v1.SubAccount {
+	s.nextSubAccountID++
+	account.ID = v1.VoIpMsStringInt(s.nextSubAccountID)
+	if account.Account == "" {
+		account.Account = s.AccountID + "_" + account.Username
+	}
+
+	s.subAccounts = append(s.subAccounts, account)
+	if _, ok := s.registrations[account.Account]; !ok {
+		s.registrations[account.Account] = []v1.RegistrationStatus{}
+	}
+
+	return account
+}
+
+func (s *Server) SubAccount(account string) (v1.SubAccount, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, subAccount := range s.subAccounts {
+		if subAccount.Account == account {
+			return subAccount, true
+		}
+	}
+	return v1.SubAccount{}, false
+}
+
+func (s *Server) findSubAccount(id string) int {
+	for i := range s.subAccounts {
+		if strconv.FormatInt(int64(s.subAccounts[i].ID), 10) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) getSubAccounts(params url2.Values) (string, map[string]interface{}) {
+	var accounts []v1.SubAccount
+
+	account := params.Get("account")
+	for _, subAccount := range s.subAccounts {
+		if account == "" || subAccount.Account == account {
+			accounts = append(accounts, subAccount)
+		}
+	}
+
+	if len(accounts) == 0 {
+		if account != "" {
+			return "invalid_account", nil
+		}
+		return "no_account", nil
+	}
+
+	return "success", map[string]interface{}{"accounts": accounts}
+}
+
+func (s *Server) createSubAccount(params url2.Values) (string, map[string]interface{}) {
+	var account v1.SubAccount
+
+	username := params.Get("username")
+	if username == "" {
+		return "missing_username", nil
+	}
+
+	for _, subAccount := range s.subAccounts {
+		if subAccount.Account == s.AccountID+"_"+username {
+			return "used_username", nil
+		}
+	}
+
+	applyParams(&account, params)
+	account = s.addSubAccount(account)
+
+	return "success", map[string]interface{}{
+		"id":      account.ID,
+		"account": account.Account,
+	}
+}
+
+func (s *Server) setSubAccount(params url2.Values) (string, map[string]interface{}) {
+	id := params.Get("id")
+	if id == "" {
+		return "missing_id", nil
+	}
+
+	i := s.findSubAccount(id)
+	if i < 0 {
+		return "invalid_id", nil
+	}
+
+	params.Del("username")
+	applyParams(&s.subAccounts[i], params)
+	return "success", nil
+}
+
+func (s *Server) delSubAccount(params url2.Values) (string, map[string]interface{}) {
+	id := params.Get("id")
+	if id == "" {
+		return "missing_id", nil
+	}
+
+	i := s.findSubAccount(id)
+	if i < 0 {
+		return "invalid_id", nil
+	}
+
+	delete(s.registrations, s.subAccounts[i].Account)
+	s.subAccounts = append(s.subAccounts[:i], s.subAccounts[i+1:]...)
+	return "success", nil
+}
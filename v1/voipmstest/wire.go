@@ -0,0 +1,131 @@
+package voipmstest
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	v1 "github.com/ticpu/voipms-gorest/v1"
+)
+
+var (
+	dateType     = reflect.TypeOf(v1.VoIpMsDate{})
+	dateTimeType = reflect.TypeOf(v1.VoIpMsDateTime{})
+)
+
+// toWire renders a payload the way VoIP.ms sends it. The v1 types encode
+// themselves as JSON except dates, which VoIP.ms sends in its own format
+// rather than the RFC 3339 v1 writes.
+func toWire(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return wireValue(reflect.ValueOf(value))
+}
+
+func wireValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		return wireValue(v.Elem())
+	}
+
+	if v.Type() == dateType || v.Type() == dateTimeType {
+		text, _ := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text)
+	}
+
+	switch v.Interface().(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		wire := make(map[string]interface{}, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			wire[fmt.Sprint(iter.Key().Interface())] = wireValue(iter.Value())
+		}
+		return wire
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		wire := make([]interface{}, v.Len())
+		for i := range wire {
+			wire[i] = wireValue(v.Index(i))
+		}
+		return wire
+	case reflect.Struct:
+		wire := map[string]interface{}{}
+		wireFields(v, wire)
+		return wire
+	default:
+		return v.Interface()
+	}
+}
+
+// wireFields adds the fields of struct v to wire under their JSON names.
+func wireFields(v reflect.Value, wire map[string]interface{}) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		options := strings.Split(field.Tag.Get("json"), ",")
+		name := options[0]
+		if name == "-" {
+			continue
+		}
+
+		if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			wireFields(v.Field(i), wire)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if omitEmpty(options[1:]) && isEmpty(v.Field(i)) {
+			continue
+		}
+
+		wire[name] = wireValue(v.Field(i))
+	}
+}
+
+func omitEmpty(options []string) bool {
+	for _, option := range options {
+		if option == "omitempty" {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty follows the omitempty rules of encoding/json.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}